@@ -0,0 +1,169 @@
+package sqltool
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Codec -- serialize slice/struct/map/pointer field to column value and back, Decode receive pointer to field value
+type Codec interface {
+	Encode(v interface{}) ([]byte, error)
+	Decode(data []byte, v interface{}) error
+}
+
+var (
+	// JSONCodec -- encoding/json, numbers in interface{} are decoded as json.Number so int64 is not turned into float64
+	JSONCodec Codec = jsonCodec{escapeHTML: true, useNumber: true}
+	// JSONNoEscapeCodec -- same as JSONCodec but <, > and & are not escaped
+	JSONNoEscapeCodec Codec = jsonCodec{useNumber: true}
+	// GobCodec -- encoding/gob
+	GobCodec Codec = gobCodec{}
+	// StringListCodec -- []string to comma-separated string, e.g. a,b,c
+	StringListCodec Codec = stringListCodec{}
+
+	// defaultCodec -- codec of column without codec, same as json.Marshal and json.Unmarshal
+	defaultCodec Codec = jsonCodec{escapeHTML: true}
+)
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]Codec{
+		"json":          JSONCodec,
+		"json_noescape": JSONNoEscapeCodec,
+		"gob":           GobCodec,
+		"csv":           StringListCodec,
+	}
+)
+
+// RegisterCodec -- register codec by name used in `db:",codec=name"` tag, built-in codec with same name is replaced
+func RegisterCodec(name string, codec Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+
+	codecs[name] = codec
+}
+
+func lookupCodec(name string) (Codec, bool) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+
+	codec, ok := codecs[name]
+	return codec, ok
+}
+
+// codecOf -- codec of column set by CodecOpt or `db:",codec=name"` tag, default is PostgreSQL array for slice of
+// primitive with DialectPostgres, otherwise encoding/json
+func (st *SQLTool) codecOf(column string) Codec {
+	if codec, ok := st.columnCodecs[column]; ok {
+		return codec
+	}
+
+	if name, ok := st.column2Tag[column]["codec"]; ok {
+		if codec, ok := lookupCodec(name); ok {
+			return codec
+		}
+
+		fmt.Printf("[sqltool] codec %s of column %s is not registered, use default codec instead", name, column)
+	}
+
+	if st.dialect == DialectPostgres && isPostgresArrayType(st.column2Type[column]) {
+		return PostgresArrayCodec
+	}
+
+	return defaultCodec
+}
+
+// encodeValue -- encode value of column by its codec, PostgreSQL array is passed as string so driver does not send
+// it as bytea
+func (st *SQLTool) encodeValue(column string, value interface{}) (interface{}, error) {
+	codec := st.codecOf(column)
+
+	data, err := codec.Encode(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := codec.(pgArrayCodec); ok {
+		return string(data), nil
+	}
+
+	return data, nil
+}
+
+type jsonCodec struct {
+	escapeHTML bool
+	useNumber  bool
+}
+
+func (c jsonCodec) Encode(v interface{}) ([]byte, error) {
+	if c.escapeHTML {
+		return json.Marshal(v)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+func (c jsonCodec) Decode(data []byte, v interface{}) error {
+	if !c.useNumber {
+		return json.Unmarshal(data, v)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+type gobCodec struct{}
+
+func (gobCodec) Encode(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Decode(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+type stringListCodec struct{}
+
+func (stringListCodec) Encode(v interface{}) ([]byte, error) {
+	list, ok := reflect.Indirect(reflect.ValueOf(v)).Interface().([]string)
+	if !ok {
+		return nil, fmt.Errorf("string list codec expected []string but got %T", v)
+	}
+
+	return []byte(strings.Join(list, ",")), nil
+}
+
+func (stringListCodec) Decode(data []byte, v interface{}) error {
+	list, ok := v.(*[]string)
+	if !ok {
+		return fmt.Errorf("string list codec expected *[]string but got %T", v)
+	}
+
+	*list = make([]string, 0)
+	if len(data) == 0 {
+		return nil
+	}
+
+	*list = strings.Split(string(data), ",")
+	return nil
+}
@@ -0,0 +1,106 @@
+package sqltool_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/wizk3y/go-sqltool"
+)
+
+type codecTestArticle struct {
+	ID    int64                  `json:"id"`
+	Tags  []string               `json:"tags" db:",codec=csv"`
+	Meta  map[string]interface{} `json:"meta" db:",codec=json"`
+	Body  map[string]string      `json:"body"`
+	Notes []string               `json:"notes" db:",codec=upper"`
+}
+
+type upperCodec struct{}
+
+func (upperCodec) Encode(v interface{}) ([]byte, error) {
+	return []byte(strings.ToUpper(strings.Join(v.([]string), "|"))), nil
+}
+
+func (upperCodec) Decode(data []byte, v interface{}) error {
+	*v.(*[]string) = strings.Split(strings.ToLower(string(data)), "|")
+	return nil
+}
+
+func Test_SQLTool_Codec(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	sqltool.RegisterCodec("upper", upperCodec{})
+
+	// encode
+	sqlTool := sqltool.NewTool(context.Background(), db)
+	sqlTool.PrepareInsert(&codecTestArticle{
+		Tags:  []string{"go", "sql"},
+		Meta:  map[string]interface{}{"views": int64(9007199254740993)},
+		Body:  map[string]string{"html": "<b>"},
+		Notes: []string{"a", "b"},
+	}, sqltool.CodecOpt("body", sqltool.JSONNoEscapeCodec))
+
+	expected := []driver.Value{"go,sql", `{"views":9007199254740993}`, `{"html":"<b>"}`, "A|B"}
+	values := sqlTool.GetInsertValues()
+	for index, value := range values {
+		if string(value.([]byte)) != expected[index] {
+			t.Fatalf("expected encoded value %v but got %s", expected[index], value)
+		}
+	}
+
+	// decode
+	mock.ExpectPrepare("SELECT id, tags, meta, body, notes FROM article").
+		ExpectQuery().
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tags", "meta", "body", "notes"}).
+			AddRow(1, "go,sql", `{"views":9007199254740993}`, `{"html":"<b>"}`, "A|B"))
+
+	sqlTool.PrepareSelect(&codecTestArticle{})
+
+	var article codecTestArticle
+	err = sqlTool.SelectOne(&article, "SELECT id, tags, meta, body, notes FROM article")
+	if err != nil {
+		t.Fatalf("error when select with codec, details: %v", err)
+	}
+
+	if len(article.Tags) != 2 || article.Tags[1] != "sql" {
+		t.Fatalf("unexpected tags %v", article.Tags)
+	}
+	if views, ok := article.Meta["views"].(json.Number); !ok || views.String() != "9007199254740993" {
+		t.Fatalf("expected json.Number views but got %#v", article.Meta["views"])
+	}
+	if article.Body["html"] != "<b>" || len(article.Notes) != 2 || article.Notes[0] != "a" {
+		t.Fatalf("unexpected article %+v", article)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unfulfilled expectations, details: %v", err)
+	}
+}
+
+func Test_GobCodec(t *testing.T) {
+	type point struct {
+		X, Y int64
+	}
+
+	data, err := sqltool.GobCodec.Encode(point{X: 1, Y: 2})
+	if err != nil {
+		t.Fatalf("error when encode by gob, details: %v", err)
+	}
+
+	var p point
+	err = sqltool.GobCodec.Decode(data, &p)
+	if err != nil {
+		t.Fatalf("error when decode by gob, details: %v", err)
+	}
+	if p.X != 1 || p.Y != 2 {
+		t.Fatalf("unexpected point %+v", p)
+	}
+}
@@ -0,0 +1,329 @@
+package sqltool
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/wizk3y/go-sqltool/internal"
+)
+
+// tableIndex -- index declared by `db:",index"`, `db:",unique"` tag, columns having same index name form one index,
+// e.g. `db:",index=idx_user_time"`
+type tableIndex struct {
+	name    string
+	unique  bool
+	columns []string
+}
+
+// CreateTableSQL -- build CREATE TABLE statement of model prepared with opts, followed by CREATE INDEX statements
+// except for DialectMySQL which declare indexes inside table.
+// Column type is inferred from kind of field, DateTimeColumnsOpt and codec, `db:",size=100"` tag set VARCHAR length.
+// Column is NOT NULL unless it is pointer, nullable, date/time, serialized or soft delete column
+func CreateTableSQL(dialect Dialect, table string, i interface{}, opts ...sqlToolOpt) (string, error) {
+	st := NewToolFromExecutor(context.Background(), nil)
+	st.dialect = dialect
+	st.prepare(selectAction, i, opts)
+
+	columns := st.schemaColumns()
+	if len(columns) == 0 {
+		return "", fmt.Errorf("model %s has no column", st.modelName)
+	}
+
+	primaryKeys := make([]string, 0)
+	for _, column := range st.primaryKeyColumns() {
+		if internal.IsStringSliceContains(columns, column) {
+			primaryKeys = append(primaryKeys, column)
+		}
+	}
+	serial := len(primaryKeys) == 1 && primaryKeys[0] == st.serialColumn && isIntKind(st.column2Type[st.serialColumn].Kind())
+
+	defs := make([]string, 0, len(columns)+1)
+	for _, column := range columns {
+		def, err := st.columnDefinition(column, serial && column == st.serialColumn)
+		if err != nil {
+			return "", err
+		}
+		defs = append(defs, "  "+def)
+	}
+
+	// sqlite declare serial primary key inline
+	if len(primaryKeys) > 0 && !(serial && dialect == DialectSQLite) {
+		defs = append(defs, "  PRIMARY KEY ("+st.quoteColumns(primaryKeys)+")")
+	}
+
+	indexes := st.tableIndexes(table)
+	if dialect == DialectMySQL {
+		for _, index := range indexes {
+			key := "KEY "
+			if index.unique {
+				key = "UNIQUE KEY "
+			}
+			defs = append(defs, "  "+key+dialect.quote(index.name)+" ("+st.quoteColumns(index.columns)+")")
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("CREATE TABLE " + dialect.quote(table) + " (\n")
+	sb.WriteString(strings.Join(defs, ",\n"))
+	sb.WriteString("\n);\n")
+
+	if dialect != DialectMySQL {
+		for _, index := range indexes {
+			create := "CREATE INDEX "
+			if index.unique {
+				create = "CREATE UNIQUE INDEX "
+			}
+			sb.WriteString(create + dialect.quote(index.name) + " ON " + dialect.quote(table) +
+				" (" + st.quoteColumns(index.columns) + ");\n")
+		}
+	}
+
+	return sb.String(), nil
+}
+
+func (st *SQLTool) quoteColumns(columns []string) string {
+	quoted := make([]string, 0, len(columns))
+	for _, column := range columns {
+		quoted = append(quoted, st.dialect.quote(column))
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+// tableIndexes -- indexes in order of first column, default name is idx_<table>_<column> or uk_<table>_<column>
+func (st *SQLTool) tableIndexes(table string) []*tableIndex {
+	indexes := make([]*tableIndex, 0)
+	byName := make(map[string]*tableIndex)
+
+	for _, column := range st.schemaColumns() {
+		for _, kind := range []string{"index", "unique"} {
+			name, ok := st.column2Tag[column][kind]
+			if !ok {
+				continue
+			}
+
+			if len(name) == 0 {
+				prefix := "idx_"
+				if kind == "unique" {
+					prefix = "uk_"
+				}
+				name = prefix + table + "_" + column
+			}
+
+			index, ok := byName[name]
+			if !ok {
+				index = &tableIndex{name: name, unique: kind == "unique"}
+				byName[name] = index
+				indexes = append(indexes, index)
+			}
+			index.columns = append(index.columns, column)
+		}
+	}
+
+	return indexes
+}
+
+// columnDefinition -- name, type and constraint of column
+func (st *SQLTool) columnDefinition(column string, serial bool) (string, error) {
+	name := st.dialect.quote(column)
+
+	if serial {
+		switch st.dialect {
+		case DialectPostgres:
+			return name + " BIGSERIAL", nil
+		case DialectSQLite:
+			return name + " INTEGER PRIMARY KEY AUTOINCREMENT", nil
+		default:
+			return name + " BIGINT NOT NULL AUTO_INCREMENT", nil
+		}
+	}
+
+	sqlType, err := st.columnType(column)
+	if err != nil {
+		return "", err
+	}
+
+	if st.isNullableColumn(column) {
+		return name + " " + sqlType, nil
+	}
+
+	return name + " " + sqlType + " NOT NULL", nil
+}
+
+// isNullableColumn -- NULL may be written to column by PrepareValues
+func (st *SQLTool) isNullableColumn(column string) bool {
+	if internal.IsStringSliceContains(st.nullableColumns, column) || column == st.getSoftDeleteColumn() {
+		return true
+	}
+
+	if st.isDateTimeColumn(column) {
+		_, autoCreate := st.autoCreateDateTimeColumns[column]
+		_, autoUpdate := st.autoUpdateDateTimeColumns[column]
+		return !autoCreate && !autoUpdate
+	}
+
+	vType := st.column2Type[column]
+	if _, ok := lookupEnum(vType); ok {
+		return vType.Kind() == reflect.Ptr
+	}
+
+	switch vType.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Struct, reflect.Slice:
+		return true
+	}
+
+	return false
+}
+
+// columnType -- SQL type of column by dialect
+func (st *SQLTool) columnType(column string) (string, error) {
+	vType := st.column2Type[column]
+
+	if st.isDateTimeColumn(column) {
+		switch st.dialect {
+		case DialectPostgres:
+			return "TIMESTAMP", nil
+		case DialectSQLite:
+			return "DATETIME", nil
+		default:
+			switch st.dateTimeUnit {
+			case "ms":
+				return "DATETIME(3)", nil
+			case "us", "µs", "ns":
+				return "DATETIME(6)", nil
+			}
+			return "DATETIME", nil
+		}
+	}
+
+	if encrypted, _ := st.encryptionMode(column); encrypted {
+		return "TEXT", nil
+	}
+
+	if e, ok := lookupEnum(vType); ok {
+		return st.primitiveType(column, e.dbType())
+	}
+
+	if isSerializedKind(internal.Deref(vType)) {
+		switch st.codecOf(column).(type) {
+		case pgArrayCodec:
+			elemType, err := st.primitiveType(column, internal.Deref(vType.Elem()))
+			return elemType + "[]", err
+		case jsonCodec:
+			switch st.dialect {
+			case DialectPostgres:
+				return "JSONB", nil
+			case DialectSQLite:
+				return "TEXT", nil
+			default:
+				return "JSON", nil
+			}
+		case gobCodec:
+			return st.primitiveType(column, reflect.TypeOf([]byte(nil)))
+		default:
+			return "TEXT", nil
+		}
+	}
+
+	return st.primitiveType(column, internal.Deref(vType))
+}
+
+// isSerializedKind -- value of type is encoded by codec
+func isSerializedKind(vType reflect.Type) bool {
+	switch vType.Kind() {
+	case reflect.Map, reflect.Struct:
+		return true
+	case reflect.Slice:
+		return vType.Elem().Kind() != reflect.Uint8
+	}
+
+	return false
+}
+
+func isIntKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+
+	return false
+}
+
+// primitiveType -- SQL type of string/bool/number/bytes
+func (st *SQLTool) primitiveType(column string, vType reflect.Type) (string, error) {
+	mysql := st.dialect != DialectPostgres && st.dialect != DialectSQLite
+
+	switch vType.Kind() {
+	case reflect.String:
+		size, ok := st.column2Tag[column]["size"]
+		if ok {
+			if _, err := strconv.Atoi(size); err != nil {
+				return "", fmt.Errorf("invalid size %s of column %s", size, column)
+			}
+			return "VARCHAR(" + size + ")", nil
+		}
+		if mysql {
+			return "VARCHAR(255)", nil
+		}
+		return "TEXT", nil
+	case reflect.Bool:
+		switch st.dialect {
+		case DialectPostgres:
+			return "BOOLEAN", nil
+		case DialectSQLite:
+			return "INTEGER", nil
+		default:
+			return "TINYINT(1)", nil
+		}
+	case reflect.Int8, reflect.Int16, reflect.Uint8, reflect.Uint16:
+		if st.dialect == DialectSQLite {
+			return "INTEGER", nil
+		}
+		return "SMALLINT", nil
+	case reflect.Int32:
+		if st.dialect == DialectSQLite {
+			return "INTEGER", nil
+		}
+		return "INT", nil
+	case reflect.Int, reflect.Int64, reflect.Uint32:
+		if st.dialect == DialectSQLite {
+			return "INTEGER", nil
+		}
+		return "BIGINT", nil
+	case reflect.Uint, reflect.Uint64:
+		switch st.dialect {
+		case DialectPostgres:
+			return "NUMERIC(20)", nil
+		case DialectSQLite:
+			return "INTEGER", nil
+		default:
+			return "BIGINT UNSIGNED", nil
+		}
+	case reflect.Float32:
+		return "REAL", nil
+	case reflect.Float64:
+		switch st.dialect {
+		case DialectPostgres:
+			return "DOUBLE PRECISION", nil
+		case DialectSQLite:
+			return "REAL", nil
+		default:
+			return "DOUBLE", nil
+		}
+	case reflect.Slice:
+		if vType.Elem().Kind() == reflect.Uint8 {
+			switch st.dialect {
+			case DialectPostgres:
+				return "BYTEA", nil
+			default:
+				return "BLOB", nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("type %s of column %s is not supported", vType, column)
+}
@@ -0,0 +1,80 @@
+package sqltool_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wizk3y/go-sqltool"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata")
+
+type ddlTestArticle struct {
+	ID        int64             `json:"id"`
+	Slug      string            `json:"slug" db:",unique,size=120"`
+	Title     string            `json:"title"`
+	AuthorID  int32             `json:"author_id" db:",index=idx_article_author_time"`
+	Published bool              `json:"published"`
+	Score     float64           `json:"score"`
+	Views     uint64            `json:"views"`
+	Summary   *string           `json:"summary"`
+	Tags      []string          `json:"tags"`
+	Meta      map[string]string `json:"meta"`
+	Cover     []byte            `json:"cover"`
+	Status    enumTestStatus    `json:"status"`
+	CreatedAt int64             `json:"created_at" db:",index=idx_article_author_time"`
+	DeletedAt int64             `json:"deleted_at" db:",soft_delete"`
+}
+
+func Test_CreateTableSQL(t *testing.T) {
+	for _, dialect := range []sqltool.Dialect{sqltool.DialectMySQL, sqltool.DialectPostgres, sqltool.DialectSQLite} {
+		ddl, err := sqltool.CreateTableSQL(dialect, "article", &ddlTestArticle{},
+			sqltool.DateTimeColumnsOpt([]string{"created_at", "deleted_at"}),
+			sqltool.AutoCreateDateTimeColumnsOpt([]string{"created_at"}),
+			sqltool.DateTimeUnitOpt("ms"))
+		if err != nil {
+			t.Fatalf("error when create table sql of %s, details: %v", dialect, err)
+		}
+
+		golden := filepath.Join("testdata", "create_table_"+string(dialect)+".sql")
+		if *updateGolden {
+			err = os.WriteFile(golden, []byte(ddl), 0644)
+			if err != nil {
+				t.Fatalf("error when update golden file %s, details: %v", golden, err)
+			}
+		}
+
+		expected, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatalf("error when read golden file %s, details: %v", golden, err)
+		}
+		if ddl != string(expected) {
+			t.Fatalf("create table sql of %s does not match %s:\n%s", dialect, golden, ddl)
+		}
+	}
+}
+
+func Test_CreateTableSQL_CompositePrimaryKey(t *testing.T) {
+	ddl, err := sqltool.CreateTableSQL(sqltool.DialectSQLite, "membership", &modelTestMembership{})
+	if err != nil {
+		t.Fatalf("error when create table sql, details: %v", err)
+	}
+
+	golden := filepath.Join("testdata", "create_table_composite_pk.sql")
+	if *updateGolden {
+		err = os.WriteFile(golden, []byte(ddl), 0644)
+		if err != nil {
+			t.Fatalf("error when update golden file %s, details: %v", golden, err)
+		}
+	}
+
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("error when read golden file %s, details: %v", golden, err)
+	}
+	if ddl != string(expected) {
+		t.Fatalf("create table sql does not match %s:\n%s", golden, ddl)
+	}
+}
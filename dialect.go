@@ -0,0 +1,36 @@
+package sqltool
+
+import (
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// Dialect -- SQL dialect of database, decide placeholder of query built by tool
+type Dialect string
+
+const (
+	// DialectMySQL -- MySQL/MariaDB, default dialect
+	DialectMySQL Dialect = "mysql"
+	// DialectPostgres -- PostgreSQL
+	DialectPostgres Dialect = "postgres"
+	// DialectSQLite -- SQLite
+	DialectSQLite Dialect = "sqlite"
+)
+
+func (d Dialect) placeholder() squirrel.PlaceholderFormat {
+	if d == DialectPostgres {
+		return squirrel.Dollar
+	}
+
+	return squirrel.Question
+}
+
+// quote -- quote identifier, e.g. table or column name
+func (d Dialect) quote(name string) string {
+	if d == DialectMySQL || len(d) == 0 {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,286 @@
+package sqltool
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/wizk3y/go-sqltool/internal"
+)
+
+var (
+	// ErrUnknownKey -- key id of ciphertext is not found in key provider
+	ErrUnknownKey = errors.New("unknown encryption key")
+	// ErrInvalidCiphertext -- value of encrypted column is not a valid envelope or can not be authenticated
+	ErrInvalidCiphertext = errors.New("invalid ciphertext")
+)
+
+// envelopeVersion -- first byte of envelope: version | key id length | key id | nonce | sealed data
+const envelopeVersion byte = 1
+
+// KeyProvider -- provide keys to encrypt/decrypt columns. CurrentKey is used to encrypt, Key is used to decrypt by key
+// id stored in ciphertext, so rotated keys must be kept until every row is encrypted again
+type KeyProvider interface {
+	CurrentKey() (id string, key []byte, err error)
+	Key(id string) ([]byte, error)
+}
+
+// Keyring -- KeyProvider keeping keys in memory, key must have 16, 24 or 32 bytes for AES-128, AES-192 or AES-256
+type Keyring struct {
+	current string
+	keys    map[string][]byte
+}
+
+// NewKeyring -- create keyring encrypt by key of current id
+func NewKeyring(current string, keys map[string][]byte) *Keyring {
+	return &Keyring{current: current, keys: keys}
+}
+
+// CurrentKey -- key to encrypt
+func (k *Keyring) CurrentKey() (string, []byte, error) {
+	key, err := k.Key(k.current)
+	return k.current, key, err
+}
+
+// Key -- key of id to decrypt
+func (k *Keyring) Key(id string) ([]byte, error) {
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, id)
+	}
+
+	return key, nil
+}
+
+// Encryptor -- encrypt/decrypt value of encrypted column. Deterministic encryption return same ciphertext for same
+// column, plaintext and key, so column can be queried by equality
+type Encryptor interface {
+	Encrypt(column string, plaintext []byte, deterministic bool) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// NewAESGCMEncryptor -- AES-GCM encryptor with keys of provider. Encryption and nonce keys are derived from key by
+// HKDF-SHA256, nonce is random, or HMAC-SHA256 of column and plaintext for deterministic encryption
+func NewAESGCMEncryptor(provider KeyProvider) Encryptor {
+	return aesGCMEncryptor{provider: provider}
+}
+
+type aesGCMEncryptor struct {
+	provider KeyProvider
+}
+
+func (e aesGCMEncryptor) Encrypt(column string, plaintext []byte, deterministic bool) ([]byte, error) {
+	id, key, err := e.provider.CurrentKey()
+	if err != nil {
+		return nil, err
+	}
+	if len(id) > 255 {
+		return nil, fmt.Errorf("key id %s is longer than 255 bytes", id)
+	}
+
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if deterministic {
+		// column is length-prefixed so column and plaintext can not be shifted
+		mac := hmac.New(sha256.New, hkdfSHA256(key, "nonce", sha256.Size))
+		mac.Write([]byte{byte(len(column) >> 8), byte(len(column))})
+		mac.Write([]byte(column))
+		mac.Write(plaintext)
+		copy(nonce, mac.Sum(nil))
+	} else if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	header := append([]byte{envelopeVersion, byte(len(id))}, id...)
+	envelope := append(header, nonce...)
+
+	// header is authenticated so key id can not be changed
+	return aead.Seal(envelope, nonce, plaintext, header), nil
+}
+
+func (e aesGCMEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < 2 || ciphertext[0] != envelopeVersion || len(ciphertext) < 2+int(ciphertext[1]) {
+		return nil, ErrInvalidCiphertext
+	}
+
+	header := ciphertext[:2+int(ciphertext[1])]
+	key, err := e.provider.Key(string(header[2:]))
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	rest := ciphertext[len(header):]
+	if len(rest) < aead.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+
+	plaintext, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], header)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+
+	return plaintext, nil
+}
+
+// newGCM -- AES-GCM of encryption key derived from key, key must have 16, 24 or 32 bytes
+func newGCM(key []byte) (cipher.AEAD, error) {
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, aes.KeySizeError(len(key))
+	}
+
+	block, err := aes.NewCipher(hkdfSHA256(key, "encryption", len(key)))
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// hkdfSHA256 -- derive subkey of info from key by HKDF-SHA256 (RFC 5869) without salt
+func hkdfSHA256(key []byte, info string, length int) []byte {
+	extract := hmac.New(sha256.New, make([]byte, sha256.Size))
+	extract.Write(key)
+	prk := extract.Sum(nil)
+
+	var (
+		out  []byte
+		prev []byte
+	)
+	for counter := byte(1); len(out) < length; counter++ {
+		expand := hmac.New(sha256.New, prk)
+		expand.Write(prev)
+		expand.Write([]byte(info))
+		expand.Write([]byte{counter})
+		prev = expand.Sum(nil)
+		out = append(out, prev...)
+	}
+
+	return out[:length]
+}
+
+// encryptionMode -- column is encrypted by EncryptedColumnsOpt/DeterministicEncryptedColumnsOpt or
+// `db:",encrypted"`/`db:",encrypted=deterministic"` tag
+func (st *SQLTool) encryptionMode(column string) (encrypted bool, deterministic bool) {
+	if deterministic, ok := st.encryptedColumns[column]; ok {
+		return true, deterministic
+	}
+
+	mode, ok := st.column2Tag[column]["encrypted"]
+	return ok, mode == "deterministic"
+}
+
+// EncryptValue -- encrypt value as it is stored in encrypted column, use with deterministic encrypted column to build
+// equality condition. Value of column which is not encrypted is returned as is.
+// Value is encrypted by current key only, rows encrypted by rotated keys are not matched until they are encrypted again
+// by current key
+func (st *SQLTool) EncryptValue(column string, value interface{}) (interface{}, error) {
+	return st.encryptColumnValue(column, value)
+}
+
+// encryptColumnValue -- encrypt prepared value of column to base64 envelope, nil is kept as NULL
+func (st *SQLTool) encryptColumnValue(column string, value interface{}) (interface{}, error) {
+	encrypted, deterministic := st.encryptionMode(column)
+	if !encrypted || value == nil {
+		return value, nil
+	}
+
+	if st.encryptor == nil {
+		return nil, fmt.Errorf("encryptor is not set for encrypted column %s", column)
+	}
+
+	var plaintext []byte
+	switch v := value.(type) {
+	case []byte:
+		plaintext = v
+	case string:
+		plaintext = []byte(v)
+	case time.Time:
+		plaintext = []byte(v.Format(time.RFC3339Nano))
+	default:
+		plaintext = []byte(fmt.Sprint(v))
+	}
+
+	ciphertext, err := st.encryptor.Encrypt(column, plaintext, deterministic)
+	if err != nil {
+		return nil, fmt.Errorf("error while encrypt value of column %s: %v", column, err)
+	}
+
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// fillEncrypted -- decrypt value of encrypted column scanned as string then set to field
+func (st *SQLTool) fillEncrypted(field reflect.Value, column string, vType reflect.Type, value *sql.NullString) error {
+	if !value.Valid {
+		return nil
+	}
+
+	if st.encryptor == nil {
+		return fmt.Errorf("encryptor is not set for encrypted column %s", column)
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(value.String)
+	if err != nil {
+		return fmt.Errorf("%w of column %s", ErrInvalidCiphertext, column)
+	}
+
+	plaintext, err := st.encryptor.Decrypt(ciphertext)
+	if err != nil {
+		return fmt.Errorf("error while decrypt value of column %s: %w", column, err)
+	}
+
+	if st.isDateTimeColumn(column) {
+		return st.fillEncryptedDateTime(field, column, vType, string(plaintext))
+	}
+
+	switch {
+	case vType.Kind() == reflect.String:
+		field.SetString(string(plaintext))
+	case vType.Kind() == reflect.Slice && vType.Elem().Kind() == reflect.Uint8:
+		field.SetBytes(plaintext)
+	case vType.Kind() == reflect.Ptr:
+		fillValueByType(field, column, internal.Deref(vType), string(plaintext), true, st.codecOf(column))
+	default:
+		fillValueByType(field, column, vType, string(plaintext), false, st.codecOf(column))
+	}
+
+	return nil
+}
+
+// fillEncryptedDateTime -- parse decrypted RFC3339 time of datetime column then set timestamp by unit to field
+func (st *SQLTool) fillEncryptedDateTime(field reflect.Value, column string, vType reflect.Type, value string) error {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return fmt.Errorf("error while parse decrypted time of column %s: %v", column, err)
+	}
+
+	ts := internal.TimestampByUnit(t, st.dateTimeUnit)
+	if vType.Kind() == reflect.Ptr {
+		ptr := reflect.New(vType.Elem())
+		ptr.Elem().SetInt(ts)
+		field.Set(ptr)
+		return nil
+	}
+
+	field.SetInt(ts)
+	return nil
+}
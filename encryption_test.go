@@ -0,0 +1,118 @@
+package sqltool_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/wizk3y/go-sqltool"
+)
+
+type encryptionTestUser struct {
+	ID    int64  `json:"id"`
+	Email string `json:"email" db:",encrypted=deterministic"`
+	Phone string `json:"phone" db:",encrypted"`
+	Note  []byte `json:"note"`
+	Age   *int64 `json:"age"`
+	// datetime column is encrypted as RFC3339 time
+	CreatedAt int64 `json:"created_at" db:",encrypted"`
+}
+
+func Test_SQLTool_Encryption(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	oldKey := bytes.Repeat([]byte{1}, 32)
+	newKey := bytes.Repeat([]byte{2}, 32)
+	oldEncryptor := sqltool.EncryptorOpt(sqltool.NewAESGCMEncryptor(sqltool.NewKeyring("v1", map[string][]byte{"v1": oldKey})))
+	newEncryptor := sqltool.EncryptorOpt(sqltool.NewAESGCMEncryptor(sqltool.NewKeyring("v2", map[string][]byte{"v1": oldKey, "v2": newKey})))
+
+	// encrypt by old key
+	age := int64(30)
+	user := encryptionTestUser{Email: "a@b.c", Phone: "0123", Note: []byte("secret"), Age: &age, CreatedAt: 1680220668123456789}
+
+	sqlTool := sqltool.NewTool(context.Background(), db)
+	err = sqlTool.PrepareInsertE(&user, oldEncryptor,
+		sqltool.EncryptedColumnsOpt([]string{"note", "age"}),
+		sqltool.DateTimeColumnsOpt([]string{"created_at"}),
+		sqltool.DateTimeUnitOpt("ns"),
+	)
+	if err != nil {
+		t.Fatalf("error when prepare insert encrypted columns, details: %v", err)
+	}
+	first := sqlTool.GetInsertValues()
+
+	err = sqlTool.PrepareInsertE(&user)
+	if err != nil {
+		t.Fatalf("error when prepare insert encrypted columns, details: %v", err)
+	}
+	second := sqlTool.GetInsertValues()
+
+	if first[0] != second[0] {
+		t.Fatalf("expected same ciphertext of deterministic column but got %v and %v", first[0], second[0])
+	}
+	if first[1] == second[1] || first[1] == "0123" {
+		t.Fatalf("expected different ciphertext of random column but got %v and %v", first[1], second[1])
+	}
+
+	where, err := sqlTool.EncryptValue("email", "a@b.c")
+	if err != nil || where != first[0] {
+		t.Fatalf("expected encrypted condition %v but got %v, details: %v", first[0], where, err)
+	}
+
+	// column is part of deterministic nonce, same value of other column has different ciphertext
+	encryptor := sqltool.NewAESGCMEncryptor(sqltool.NewKeyring("v1", map[string][]byte{"v1": oldKey}))
+	email, _ := encryptor.Encrypt("email", []byte("a@b.c"), true)
+	login, _ := encryptor.Encrypt("login", []byte("a@b.c"), true)
+	if bytes.Equal(email, login) {
+		t.Fatalf("expected deterministic ciphertext of different columns differ")
+	}
+
+	// decrypt by rotated keyring
+	mock.ExpectPrepare("SELECT id, email, phone, note, age, created_at FROM user").
+		ExpectQuery().
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "phone", "note", "age", "created_at"}).
+			AddRow(1, first[0], first[1], first[2], first[3], first[4]))
+
+	sqlTool.PrepareSelect(&encryptionTestUser{}, newEncryptor)
+
+	var found encryptionTestUser
+	err = sqlTool.SelectOne(&found, "SELECT id, email, phone, note, age, created_at FROM user")
+	if err != nil {
+		t.Fatalf("error when select encrypted columns, details: %v", err)
+	}
+	if found.Email != "a@b.c" || found.Phone != "0123" || string(found.Note) != "secret" || found.Age == nil || *found.Age != 30 ||
+		found.CreatedAt != user.CreatedAt {
+		t.Fatalf("unexpected decrypted user %+v", found)
+	}
+
+	// new values are encrypted by current key, old key is unknown
+	err = sqlTool.PrepareInsertE(&user, newEncryptor)
+	if err != nil {
+		t.Fatalf("error when prepare insert encrypted columns, details: %v", err)
+	}
+	rotated := sqlTool.GetInsertValues()
+	if rotated[0] == first[0] {
+		t.Fatalf("expected ciphertext by rotated key differ from old one")
+	}
+
+	mock.ExpectPrepare("SELECT id, email, phone, note, age, created_at FROM user").
+		ExpectQuery().
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "phone", "note", "age", "created_at"}).
+			AddRow(1, rotated[0], rotated[1], rotated[2], rotated[3], rotated[4]))
+
+	sqlTool.PrepareSelect(&encryptionTestUser{}, oldEncryptor)
+	err = sqlTool.SelectOne(&found, "SELECT id, email, phone, note, age, created_at FROM user")
+	if !errors.Is(err, sqltool.ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey but got %v", err)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unfulfilled expectations, details: %v", err)
+	}
+}
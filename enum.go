@@ -0,0 +1,124 @@
+package sqltool
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/wizk3y/go-sqltool/internal"
+)
+
+// ErrInvalidEnum -- value is not allowed value of registered enum, returned by PrepareValuesE or next Exec after
+// PrepareValues on write and by scan on read with EnumPolicyError
+var ErrInvalidEnum = errors.New("invalid enum value")
+
+// EnumDBValue -- type of database value of enum mapped by RegisterEnumMapping
+type EnumDBValue interface {
+	~string | ~int | ~int8 | ~int16 | ~int32 | ~int64
+}
+
+// enum -- allowed values of enum type, toDB map value to database value and fromDB map fmt.Sprint of database value back.
+// dbValueType is type of database values given at registration
+type enum struct {
+	toDB        map[interface{}]interface{}
+	fromDB      map[string]reflect.Value
+	dbValueType reflect.Type
+}
+
+var (
+	enumsMu sync.RWMutex
+	enums   = make(map[reflect.Type]enum)
+)
+
+// RegisterEnum -- register allowed values of enum type T, values are stored as is
+func RegisterEnum[T comparable](values ...T) {
+	mapping := make(map[interface{}]interface{}, len(values))
+	for _, v := range values {
+		mapping[v] = v
+	}
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	registerEnum(t, t, mapping)
+}
+
+// RegisterEnumMapping -- register allowed values of enum type T with their database values, e.g. string enum stored as
+// small integer
+func RegisterEnumMapping[T comparable, D EnumDBValue](mapping map[T]D) {
+	m := make(map[interface{}]interface{}, len(mapping))
+	for value, dbValue := range mapping {
+		m[value] = dbValue
+	}
+
+	registerEnum(reflect.TypeOf((*T)(nil)).Elem(), reflect.TypeOf((*D)(nil)).Elem(), m)
+}
+
+func registerEnum(t reflect.Type, dbValueType reflect.Type, mapping map[interface{}]interface{}) {
+	e := enum{
+		toDB:        mapping,
+		fromDB:      make(map[string]reflect.Value, len(mapping)),
+		dbValueType: dbValueType,
+	}
+	for value, dbValue := range mapping {
+		e.fromDB[fmt.Sprint(dbValue)] = reflect.ValueOf(value)
+	}
+
+	enumsMu.Lock()
+	defer enumsMu.Unlock()
+
+	enums[t] = e
+}
+
+// dbType -- type of database value of enum, same for every value so column type does not depend on map order
+func (e enum) dbType() reflect.Type {
+	return e.dbValueType
+}
+
+func lookupEnum(t reflect.Type) (enum, bool) {
+	enumsMu.RLock()
+	defer enumsMu.RUnlock()
+
+	e, ok := enums[internal.Deref(t)]
+	return e, ok
+}
+
+// enumValue -- database value of enum field, ErrInvalidEnum is returned for value not registered
+func enumValue(e enum, column string, value interface{}) (interface{}, error) {
+	dbValue, ok := e.toDB[value]
+	if !ok {
+		return nil, fmt.Errorf("%w %v of column %s", ErrInvalidEnum, value, column)
+	}
+
+	return dbValue, nil
+}
+
+// fillEnum -- set enum field from database value scanned as string, unknown value is handled by EnumPolicyOpt
+func (st *SQLTool) fillEnum(field reflect.Value, column string, vType reflect.Type, e enum, value *sql.NullString) error {
+	if !value.Valid {
+		return nil
+	}
+
+	enumValue, ok := e.fromDB[value.String]
+	if !ok {
+		switch st.enumPolicy {
+		case EnumPolicyZero:
+			return nil
+		case EnumPolicyKeep:
+			fillValueByType(field, column, internal.Deref(vType), value.String, vType.Kind() == reflect.Ptr, defaultCodec)
+			return nil
+		default:
+			return fmt.Errorf("%w %s of column %s", ErrInvalidEnum, value.String, column)
+		}
+	}
+
+	if vType.Kind() == reflect.Ptr {
+		ptr := reflect.New(vType.Elem())
+		ptr.Elem().Set(enumValue)
+		field.Set(ptr)
+		return nil
+	}
+
+	field.Set(enumValue)
+	return nil
+}
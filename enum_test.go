@@ -0,0 +1,110 @@
+package sqltool_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/wizk3y/go-sqltool"
+)
+
+type enumTestStatus string
+
+type enumTestLevel int
+
+type enumTestTicket struct {
+	ID       int64           `json:"id"`
+	Status   enumTestStatus  `json:"status"`
+	Level    enumTestLevel   `json:"level"`
+	Previous *enumTestStatus `json:"previous"`
+}
+
+func init() {
+	sqltool.RegisterEnumMapping(map[enumTestStatus]int8{"open": 1, "closed": 2})
+	sqltool.RegisterEnum(enumTestLevel(1), enumTestLevel(2), enumTestLevel(3))
+}
+
+func Test_SQLTool_Enum(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	sqlTool := sqltool.NewTool(context.Background(), db)
+
+	// write
+	err = sqlTool.PrepareInsertE(&enumTestTicket{Status: "closed", Level: 3})
+	if err != nil {
+		t.Fatalf("error when prepare insert enum, details: %v", err)
+	}
+	expected := []interface{}{int8(2), enumTestLevel(3), nil}
+	if !reflect.DeepEqual(sqlTool.GetInsertValues(), expected) {
+		t.Fatalf("expected values %v but got %v", expected, sqlTool.GetInsertValues())
+	}
+
+	err = sqlTool.PrepareInsertE(&enumTestTicket{Status: "pending", Level: 1})
+	if !errors.Is(err, sqltool.ErrInvalidEnum) {
+		t.Fatalf("expected ErrInvalidEnum when prepare unknown status but got %v", err)
+	}
+	_, err = sqlTool.PrepareValuesE(&enumTestTicket{Status: "open", Level: 9})
+	if !errors.Is(err, sqltool.ErrInvalidEnum) {
+		t.Fatalf("expected ErrInvalidEnum when prepare unknown level but got %v", err)
+	}
+
+	// error of PrepareValues is returned by next Exec without executing query
+	values := sqlTool.PrepareValues(&enumTestTicket{Status: "open", Level: 9})
+	_, err = sqlTool.Exec("INSERT INTO ticket (status,level,previous) VALUES (?,?,?)", values...)
+	if !errors.Is(err, sqltool.ErrInvalidEnum) {
+		t.Fatalf("expected ErrInvalidEnum when execute after prepare unknown level but got %v", err)
+	}
+
+	// read
+	query := "SELECT id, status, level, previous FROM ticket"
+	rows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "status", "level", "previous"}).
+			AddRow(1, 1, 2, nil).
+			AddRow(2, 7, 1, 2)
+	}
+	mock.ExpectPrepare(query).ExpectQuery().WillReturnRows(rows())
+	mock.ExpectPrepare(query).ExpectQuery().WillReturnRows(rows())
+	mock.ExpectPrepare(query).ExpectQuery().WillReturnRows(rows())
+
+	sqlTool.PrepareSelect(&enumTestTicket{})
+
+	var tickets []enumTestTicket
+	err = sqlTool.SelectAll(&tickets, query)
+	var rowErr *sqltool.RowError
+	if !errors.As(err, &rowErr) || rowErr.Index != 1 || !errors.Is(err, sqltool.ErrInvalidEnum) {
+		t.Fatalf("expected ErrInvalidEnum of row 1 but got %v", err)
+	}
+
+	tickets = nil
+	sqlTool.PrepareSelect(&enumTestTicket{}, sqltool.EnumPolicyOpt(sqltool.EnumPolicyZero))
+	err = sqlTool.SelectAll(&tickets, query)
+	if err != nil {
+		t.Fatalf("error when select enum with zero policy, details: %v", err)
+	}
+	if tickets[0].Status != "open" || tickets[0].Level != 2 || tickets[0].Previous != nil {
+		t.Fatalf("unexpected ticket %+v", tickets[0])
+	}
+	if tickets[1].Status != "" || *tickets[1].Previous != "closed" {
+		t.Fatalf("unexpected ticket %+v", tickets[1])
+	}
+
+	tickets = nil
+	sqlTool.PrepareSelect(&enumTestTicket{}, sqltool.EnumPolicyOpt(sqltool.EnumPolicyKeep))
+	err = sqlTool.SelectAll(&tickets, query)
+	if err != nil {
+		t.Fatalf("error when select enum with keep policy, details: %v", err)
+	}
+	if tickets[1].Status != "7" {
+		t.Fatalf("expected raw status 7 but got %+v", tickets[1])
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unfulfilled expectations, details: %v", err)
+	}
+}
@@ -2,18 +2,15 @@ package sqltool
 
 import "database/sql"
 
-// Exec -- do insert/update/delete or execute procedure
+// Exec -- do insert/update/delete or execute procedure. Error of previous PrepareInsert/PrepareUpdate/PrepareValues is
+// returned without executing query
 func (st *SQLTool) Exec(query string, args ...interface{}) (sql.Result, error) {
-	var (
-		stmt *sql.Stmt
-		err  error
-	)
-
-	if st.isTransaction {
-		stmt, err = st.tx.PrepareContext(st.ctx, query)
-	} else {
-		stmt, err = st.db.PrepareContext(st.ctx, query)
+	if err := st.prepareErr; err != nil {
+		st.prepareErr = nil
+		return nil, err
 	}
+
+	stmt, err := st.executor().PrepareContext(st.ctx, query)
 	if err != nil {
 		return nil, err
 	}
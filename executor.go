@@ -0,0 +1,44 @@
+package sqltool
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Executor -- run query against database, satisfied by *sql.DB, *sql.Tx, *sql.Conn or wrapper of them
+type Executor interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// txBeginner -- executor able to start transaction, satisfied by *sql.DB and *sql.Conn
+type txBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// TxWrapper -- implemented by executor wrapper, e.g. instrumented executor, to wrap transaction begun by tool or joined
+// from context, so the wrapper also applies inside transaction. Otherwise query in transaction run on *sql.Tx directly
+type TxWrapper interface {
+	WrapTx(tx *sql.Tx) Executor
+}
+
+// Executor -- get executor of current transaction if exists, otherwise executor of tool. Use to run command which can not
+// be prepared, e.g. script having multiple statements
+func (st *SQLTool) Executor() Executor {
+	return st.executor()
+}
+
+// executor -- get executor of current transaction if exists, wrapped by TxWrapper of tool executor, otherwise
+// executor of tool
+func (st *SQLTool) executor() Executor {
+	if st.txn != nil {
+		if wrapper, ok := st.exec.(TxWrapper); ok {
+			return wrapper.WrapTx(st.txn.tx)
+		}
+
+		return st.txn.tx
+	}
+
+	return st.exec
+}
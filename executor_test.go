@@ -0,0 +1,138 @@
+package sqltool_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/wizk3y/go-sqltool"
+)
+
+func Test_SQLTool_Executor(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectPrepare("SET time_zone = ?").
+		ExpectExec().
+		WithArgs("+07:00").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectBegin()
+	mock.ExpectPrepare("DELETE FROM user WHERE id = ?").
+		ExpectExec().
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// real code
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("error when get connection, details: %v", err)
+	}
+	defer conn.Close()
+
+	st := sqltool.NewToolFromConn(ctx, conn)
+	_, err = st.Exec("SET time_zone = ?", "+07:00")
+	if err != nil {
+		t.Fatalf("error when set session variable, details: %v", err)
+	}
+
+	err = st.Begin()
+	if err != nil {
+		t.Fatalf("error when begin transaction, details: %v", err)
+	}
+
+	// tool adopt transaction started by another tool
+	tx, _ := sqltool.TxFromContext(st.Context())
+	adopted := sqltool.NewToolFromTx(ctx, tx)
+	_, err = adopted.Exec("DELETE FROM user WHERE id = ?", 1)
+	if err != nil {
+		t.Fatalf("error when execute delete query, details: %v", err)
+	}
+	if err = adopted.BeginWithPropagation(sqltool.PropagationRequiresNew); err != sqltool.ErrTxNotSupported {
+		t.Fatalf("expected ErrTxNotSupported, details: %v", err)
+	}
+
+	err = st.Commit()
+	if err != nil {
+		t.Fatalf("error when commit transaction, details: %v", err)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unfulfilled expectations, details: %v", err)
+	}
+}
+
+// executorTestCounter -- instrumented executor counting executed queries
+type executorTestCounter struct {
+	sqltool.Executor
+	count *int
+}
+
+func (c executorTestCounter) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	*c.count++
+	return c.Executor.PrepareContext(ctx, query)
+}
+
+func (c executorTestCounter) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return c.Executor.(*sql.DB).BeginTx(ctx, opts)
+}
+
+func (c executorTestCounter) WrapTx(tx *sql.Tx) sqltool.Executor {
+	return executorTestCounter{Executor: tx, count: c.count}
+}
+
+func Test_SQLTool_TxWrapper(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectPrepare("DELETE FROM user WHERE id = ?").
+		ExpectExec().
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectBegin()
+	mock.ExpectPrepare("DELETE FROM user WHERE id = ?").
+		ExpectExec().
+		WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// real code
+	var count int
+	st := sqltool.NewToolFromExecutor(context.Background(), executorTestCounter{Executor: db, count: &count})
+	_, err = st.Exec("DELETE FROM user WHERE id = ?", 1)
+	if err != nil {
+		t.Fatalf("error when execute delete query, details: %v", err)
+	}
+
+	err = st.Begin()
+	if err != nil {
+		t.Fatalf("error when begin transaction, details: %v", err)
+	}
+
+	// transaction is wrapped by executor
+	_, err = st.Exec("DELETE FROM user WHERE id = ?", 2)
+	if err != nil {
+		t.Fatalf("error when execute delete query in transaction, details: %v", err)
+	}
+
+	err = st.Commit()
+	if err != nil {
+		t.Fatalf("error when commit transaction, details: %v", err)
+	}
+
+	if count != 2 {
+		t.Fatalf("expected 2 queries run by wrapper, got %d", count)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unfulfilled expectations, details: %v", err)
+	}
+}
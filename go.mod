@@ -1,9 +1,14 @@
 module github.com/wizk3y/go-sqltool
 
-go 1.15
+go 1.18
 
 require (
 	github.com/DATA-DOG/go-sqlmock v1.5.0
 	github.com/Masterminds/squirrel v1.5.4
 	github.com/spf13/cast v1.5.0
 )
+
+require (
+	github.com/lann/builder v0.0.0-20180802200727-47ae307949d0 // indirect
+	github.com/lann/ps v0.0.0-20150810152359-62de8c46ede0 // indirect
+)
@@ -112,3 +112,18 @@ func CastValueTo(v interface{}, vType reflect.Type, ptr bool) interface{} {
 func IsZeroOfUnderlyingType(x interface{}) bool {
 	return reflect.DeepEqual(x, reflect.Zero(reflect.TypeOf(x)).Interface())
 }
+
+// FieldByIndexAlloc -- same as reflect.Value.FieldByIndex but allocate nil pointer to struct on the way
+func FieldByIndexAlloc(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+
+	return v
+}
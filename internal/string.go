@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SplitWords -- split Go identifier to words, acronym is kept as one word, e.g. UserHTTPID => [User HTTPID]
+// and HTTPServer => [HTTP Server]
+func SplitWords(s string) []string {
+	runes := []rune(s)
+
+	var (
+		words []string
+		start int
+	)
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		if cur == '_' {
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+
+		lowerToUpper := (unicode.IsLower(prev) || unicode.IsDigit(prev)) && unicode.IsUpper(cur)
+		acronymEnd := unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if (lowerToUpper || acronymEnd) && i > start {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start < len(runes) && runes[start] != '_' {
+		words = append(words, string(runes[start:]))
+	}
+
+	return words
+}
+
+// SnakeCase -- convert Go identifier to snake_case, e.g. UserID => user_id
+func SnakeCase(s string) string {
+	return strings.ToLower(strings.Join(SplitWords(s), "_"))
+}
+
+// CamelCase -- convert Go identifier to camelCase, e.g. UserID => userID and HTTPServer => httpServer
+func CamelCase(s string) string {
+	words := SplitWords(s)
+	if len(words) == 0 {
+		return s
+	}
+
+	words[0] = strings.ToLower(words[0])
+	return strings.Join(words, "")
+}
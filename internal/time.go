@@ -1,6 +1,9 @@
 package internal
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 func TimestampByUnit(t time.Time, unit string) int64 {
 	switch unit {
@@ -31,3 +34,27 @@ func GetTimeByUnit(ts int64, unit string) time.Time {
 
 	return time.Time{}
 }
+
+var timeLayouts = []string{
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999-07",
+	"2006-01-02 15:04:05.999999999",
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// ParseTime -- parse date/time text returned by driver which not parse time itself, zero date is treated as invalid
+func ParseTime(s string) (time.Time, bool) {
+	if len(s) == 0 || strings.HasPrefix(s, "0000-00-00") {
+		return time.Time{}, false
+	}
+
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,78 @@
+package sqltool
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// ErrStaleObject -- model has been changed by another update since it was loaded
+var ErrStaleObject = errors.New("stale object")
+
+// StaleObjectError -- returned when update with version condition affect no row, errors.Is(err, ErrStaleObject) is true
+type StaleObjectError struct {
+	Model   string
+	Version interface{}
+}
+
+func (e *StaleObjectError) Error() string {
+	return fmt.Sprintf("stale object: %s with version %v has been changed by another update", e.Model, e.Version)
+}
+
+func (e *StaleObjectError) Is(target error) bool {
+	return target == ErrStaleObject
+}
+
+// GetVersionWhere -- Get condition of version column prepared by PrepareUpdate/PrepareDelete, empty when model has no version column
+func (st *SQLTool) GetVersionWhere() squirrel.Eq {
+	versionColumn := st.getVersionColumn()
+	if len(versionColumn) == 0 || st.actionType != updateAction && st.actionType != deleteAction {
+		return squirrel.Eq{}
+	}
+
+	return squirrel.Eq{versionColumn: st.versionValue}
+}
+
+// CheckUpdateResult -- check result of update/delete built with GetVersionWhere, return *StaleObjectError
+// when no row affected, otherwise version field of model is increased
+func (st *SQLTool) CheckUpdateResult(i interface{}, res sql.Result) error {
+	versionColumn := st.getVersionColumn()
+	if len(versionColumn) == 0 {
+		return nil
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return &StaleObjectError{Model: st.modelName, Version: st.versionValue}
+	}
+
+	field := reflect.Indirect(reflect.ValueOf(i)).FieldByName(st.column2FieldName[versionColumn])
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		field.SetInt(field.Int() + 1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.SetUint(field.Uint() + 1)
+	}
+
+	return nil
+}
+
+// prepareVersion -- keep current version of model for version condition
+func (st *SQLTool) prepareVersion(i interface{}) {
+	st.versionValue = nil
+
+	versionColumn := st.getVersionColumn()
+	fieldName, ok := st.column2FieldName[versionColumn]
+	if !ok {
+		return
+	}
+
+	st.versionValue = reflect.Indirect(reflect.ValueOf(i)).FieldByName(fieldName).Interface()
+}
@@ -0,0 +1,485 @@
+// Package migrate -- versioned migration runner reading up/down SQL files from fs.FS, e.g. embed.FS.
+// File name is <version>_<name>.up.sql or <version>_<name>.down.sql, e.g. 0001_create_user.up.sql. Use fs.Sub when files
+// are not in root of fs.FS. File is executed as one command, driver must allow multiple statements in one command
+// when file has more than one statement, e.g. multiStatements=true of MySQL driver
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/wizk3y/go-sqltool"
+)
+
+var (
+	// ErrLocked -- lock of migration table is held by another runner until timeout
+	ErrLocked = errors.New("migration is locked by another runner")
+	// ErrNoDownMigration -- applied migration has no down file to roll back
+	ErrNoDownMigration = errors.New("down migration is not found")
+	// ErrUnknownVersion -- version is not found in migration files
+	ErrUnknownVersion = errors.New("unknown migration version")
+)
+
+// lockRetryInterval -- interval between attempts to take advisory lock of PostgreSQL until lock timeout
+const lockRetryInterval = 100 * time.Millisecond
+
+var fileNameRegexp = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration -- SQL files of one version
+type Migration struct {
+	Version uint64
+	Name    string
+	up      string
+	down    string
+	hasUp   bool
+	hasDown bool
+}
+
+// Status -- migration and whether it has been applied, Missing is true when applied version has no file
+type Status struct {
+	Version   uint64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	Missing   bool
+}
+
+// Migrator -- run migrations on db, applied versions are recorded in table
+type Migrator struct {
+	db          *sql.DB
+	migrations  []*Migration
+	dialect     sqltool.Dialect
+	table       string
+	lockTimeout time.Duration
+}
+
+// appliedRow -- row of migration table
+type appliedRow struct {
+	Version   int64  `json:"version"`
+	Name      string `json:"name"`
+	AppliedAt int64  `json:"applied_at"`
+}
+
+// New -- create migrator reading migration files in root of fsys. Default dialect is MySQL, table is schema_migrations
+// and lock timeout is 1 minute
+func New(db *sql.DB, fsys fs.FS, opts ...migrateOpt) (*Migrator, error) {
+	m := &Migrator{
+		db:          db,
+		dialect:     sqltool.DialectMySQL,
+		table:       "schema_migrations",
+		lockTimeout: time.Minute,
+	}
+	for _, o := range opts {
+		o.Apply(m)
+	}
+
+	migrations, err := readMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	m.migrations = migrations
+
+	return m, nil
+}
+
+// Migrations -- migrations read from files in order of version
+func (m *Migrator) Migrations() []*Migration {
+	return m.migrations
+}
+
+func readMigrations(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	for _, entry := range entries {
+		matches := fileNameRegexp.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || matches == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(matches[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version of migration file %s: %v", entry.Name(), err)
+		}
+
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = migration
+		} else if migration.Name != matches[2] {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, migration.Name, matches[2])
+		}
+
+		if matches[3] == "up" {
+			migration.up = string(data)
+			migration.hasUp = true
+		} else {
+			migration.down = string(data)
+			migration.hasDown = true
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if !migration.hasUp {
+			return nil, fmt.Errorf("up migration of version %d is not found", migration.Version)
+		}
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Status -- all migrations with applied state, applied versions without file are included as Missing
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+
+	err := m.run(ctx, func(st *sqltool.SQLTool, applied map[uint64]appliedRow) error {
+		for _, migration := range m.migrations {
+			status := Status{Version: migration.Version, Name: migration.Name}
+			if row, ok := applied[migration.Version]; ok {
+				status.Applied = true
+				status.AppliedAt = time.Unix(0, row.AppliedAt)
+				delete(applied, migration.Version)
+			}
+			statuses = append(statuses, status)
+		}
+
+		for version, row := range applied {
+			statuses = append(statuses, Status{Version: version, Name: row.Name, Applied: true, AppliedAt: time.Unix(0, row.AppliedAt), Missing: true})
+		}
+		sort.Slice(statuses, func(i, j int) bool {
+			return statuses[i].Version < statuses[j].Version
+		})
+
+		return nil
+	})
+
+	return statuses, err
+}
+
+// Up -- apply all pending migrations in order of version, versions of applied migrations are returned
+func (m *Migrator) Up(ctx context.Context) ([]uint64, error) {
+	return m.up(ctx, 0, false)
+}
+
+// UpTo -- apply pending migrations having version less than or equal to version
+func (m *Migrator) UpTo(ctx context.Context, version uint64) ([]uint64, error) {
+	return m.up(ctx, version, true)
+}
+
+func (m *Migrator) up(ctx context.Context, target uint64, limited bool) ([]uint64, error) {
+	if limited && m.find(target) == nil {
+		return nil, fmt.Errorf("%w %d", ErrUnknownVersion, target)
+	}
+
+	var done []uint64
+	err := m.run(ctx, func(st *sqltool.SQLTool, applied map[uint64]appliedRow) error {
+		for _, migration := range m.migrations {
+			if limited && migration.Version > target {
+				break
+			}
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+
+			err := m.apply(st, migration.up, func(st *sqltool.SQLTool) error {
+				return m.record(st, migration)
+			})
+			if err != nil {
+				return fmt.Errorf("error while apply migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			done = append(done, migration.Version)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// Down -- roll back last n applied migrations in reverse order of version, versions of rolled back migrations are returned
+func (m *Migrator) Down(ctx context.Context, n int) ([]uint64, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("number of migrations to roll back must not be negative, got %d", n)
+	}
+
+	var done []uint64
+	err := m.run(ctx, func(st *sqltool.SQLTool, applied map[uint64]appliedRow) error {
+		versions := make([]uint64, 0, len(applied))
+		for version := range applied {
+			versions = append(versions, version)
+		}
+		sort.Slice(versions, func(i, j int) bool {
+			return versions[i] > versions[j]
+		})
+		if n < len(versions) {
+			versions = versions[:n]
+		}
+
+		for _, version := range versions {
+			migration := m.find(version)
+			if migration == nil || !migration.hasDown {
+				return fmt.Errorf("%w: version %d", ErrNoDownMigration, version)
+			}
+
+			err := m.apply(st, migration.down, func(st *sqltool.SQLTool) error {
+				return m.unrecord(st, migration)
+			})
+			if err != nil {
+				return fmt.Errorf("error while roll back migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			done = append(done, version)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+func (m *Migrator) find(version uint64) *Migration {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration
+		}
+	}
+
+	return nil
+}
+
+// run -- run fn on one connection holding migration lock, migration table is created if not exists
+func (m *Migrator) run(ctx context.Context, fn func(st *sqltool.SQLTool, applied map[uint64]appliedRow) error) (err error) {
+	if _, ok := sqltool.TxFromContext(ctx); ok {
+		return errors.New("migration must not run inside transaction attached to context")
+	}
+
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	st := sqltool.NewToolFromConn(ctx, conn)
+	err = m.lock(&st)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		errUnlock := m.unlock(&st)
+		if err == nil {
+			err = errUnlock
+		}
+	}()
+
+	_, err = st.Exec(m.createTableSQL())
+	if err != nil {
+		return err
+	}
+
+	applied, err := m.applied(&st)
+	if err != nil {
+		return err
+	}
+
+	return fn(&st, applied)
+}
+
+// apply -- execute migration SQL then update migration table, inside transaction when dialect support transactional DDL
+func (m *Migrator) apply(st *sqltool.SQLTool, query string, record func(st *sqltool.SQLTool) error) error {
+	// DDL of MySQL commit transaction implicitly
+	if m.dialect == sqltool.DialectMySQL {
+		err := m.execMigration(st, query)
+		if err != nil {
+			return err
+		}
+
+		return record(st)
+	}
+
+	err := st.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = m.execMigration(st, query)
+	if err == nil {
+		err = record(st)
+	}
+	if err != nil {
+		if errRollback := st.Rollback(); errRollback != nil {
+			fmt.Printf("[sqltool] error while rollback migration, details: %v\n", errRollback)
+		}
+		return err
+	}
+
+	return st.Commit()
+}
+
+// execMigration -- execute migration SQL, empty file is only recorded
+func (m *Migrator) execMigration(st *sqltool.SQLTool, query string) error {
+	if len(strings.TrimSpace(query)) == 0 {
+		return nil
+	}
+
+	_, err := st.Executor().ExecContext(st.Context(), query)
+	return err
+}
+
+func (m *Migrator) record(st *sqltool.SQLTool, migration *Migration) error {
+	query, args, err := squirrel.Insert(m.table).
+		Columns("version", "name", "applied_at").
+		Values(migration.Version, migration.Name, time.Now().UTC()).
+		PlaceholderFormat(m.placeholder()).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = st.Exec(query, args...)
+	return err
+}
+
+func (m *Migrator) unrecord(st *sqltool.SQLTool, migration *Migration) error {
+	query, args, err := squirrel.Delete(m.table).
+		Where(squirrel.Eq{"version": migration.Version}).
+		PlaceholderFormat(m.placeholder()).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = st.Exec(query, args...)
+	return err
+}
+
+func (m *Migrator) applied(st *sqltool.SQLTool) (map[uint64]appliedRow, error) {
+	var rows []appliedRow
+	st.PrepareSelect(&appliedRow{},
+		sqltool.DateTimeColumnsOpt([]string{"applied_at"}),
+		sqltool.DateTimeUnitOpt("ns"),
+		sqltool.AllowEmptyResultOpt(true),
+	)
+	err := st.SelectAll(&rows, "SELECT version, name, applied_at FROM "+m.table+" ORDER BY version")
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[uint64]appliedRow, len(rows))
+	for _, row := range rows {
+		applied[uint64(row.Version)] = row
+	}
+
+	return applied, nil
+}
+
+func (m *Migrator) createTableSQL() string {
+	appliedAtType := "TIMESTAMP"
+	if m.dialect == sqltool.DialectMySQL {
+		appliedAtType = "DATETIME(6)"
+	}
+
+	return "CREATE TABLE IF NOT EXISTS " + m.table + " (" +
+		"version BIGINT NOT NULL PRIMARY KEY, " +
+		"name VARCHAR(255) NOT NULL, " +
+		"applied_at " + appliedAtType + " NOT NULL)"
+}
+
+// lock -- GET_LOCK of MySQL or advisory lock of PostgreSQL named by migration table, SQLite allow one writer so no lock is taken
+func (m *Migrator) lock(st *sqltool.SQLTool) error {
+	switch m.dialect {
+	case sqltool.DialectMySQL:
+		// NULL is returned on error, scanned as 0
+		var locked int64
+		err := st.SelectScalar(&locked, "SELECT GET_LOCK(?, ?)", m.table, int64(m.lockTimeout/time.Second))
+		if err != nil {
+			return err
+		}
+		if locked != 1 {
+			return ErrLocked
+		}
+	case sqltool.DialectPostgres:
+		return m.tryLockUntilTimeout(st)
+	}
+
+	return nil
+}
+
+// tryLockUntilTimeout -- retry pg_try_advisory_lock until lock timeout, pg_advisory_lock would wait forever
+func (m *Migrator) tryLockUntilTimeout(st *sqltool.SQLTool) error {
+	deadline := time.Now().Add(m.lockTimeout)
+	for {
+		var locked bool
+		err := st.SelectScalar(&locked, "SELECT pg_try_advisory_lock($1)", m.lockKey())
+		if err != nil {
+			return err
+		}
+		if locked {
+			return nil
+		}
+
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return ErrLocked
+		}
+		if wait > lockRetryInterval {
+			wait = lockRetryInterval
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-st.Context().Done():
+			timer.Stop()
+			return st.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (m *Migrator) unlock(st *sqltool.SQLTool) error {
+	var err error
+	switch m.dialect {
+	case sqltool.DialectMySQL:
+		_, err = st.Exec("SELECT RELEASE_LOCK(?)", m.table)
+	case sqltool.DialectPostgres:
+		_, err = st.Exec("SELECT pg_advisory_unlock($1)", m.lockKey())
+	}
+
+	return err
+}
+
+// lockKey -- key of PostgreSQL advisory lock by migration table
+func (m *Migrator) lockKey() int64 {
+	h := fnv.New64a()
+	h.Write([]byte(m.table))
+	return int64(h.Sum64())
+}
+
+func (m *Migrator) placeholder() squirrel.PlaceholderFormat {
+	if m.dialect == sqltool.DialectPostgres {
+		return squirrel.Dollar
+	}
+
+	return squirrel.Question
+}
@@ -0,0 +1,45 @@
+package migrate
+
+import (
+	"time"
+
+	"github.com/wizk3y/go-sqltool"
+)
+
+type migrateOpt interface {
+	Apply(m *Migrator)
+}
+
+type dialectOpt sqltool.Dialect
+
+// DialectOpt -- set dialect of database, migrations run inside transaction except with sqltool.DialectMySQL
+func DialectOpt(dialect sqltool.Dialect) migrateOpt {
+	return dialectOpt(dialect)
+}
+
+func (o dialectOpt) Apply(m *Migrator) {
+	m.dialect = sqltool.Dialect(o)
+}
+
+type tableOpt string
+
+// TableOpt -- set table recording applied versions, name of table is also name of lock
+func TableOpt(table string) migrateOpt {
+	return tableOpt(table)
+}
+
+func (o tableOpt) Apply(m *Migrator) {
+	m.table = string(o)
+}
+
+type lockTimeoutOpt time.Duration
+
+// LockTimeoutOpt -- set time waiting for lock held by another runner with MySQL or PostgreSQL, ErrLocked is returned
+// after timeout
+func LockTimeoutOpt(timeout time.Duration) migrateOpt {
+	return lockTimeoutOpt(timeout)
+}
+
+func (o lockTimeoutOpt) Apply(m *Migrator) {
+	m.lockTimeout = time.Duration(o)
+}
@@ -0,0 +1,316 @@
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/wizk3y/go-sqltool"
+	"github.com/wizk3y/go-sqltool/migrate"
+)
+
+const createTableQuery = "CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, name VARCHAR(255) NOT NULL, applied_at TIMESTAMP NOT NULL)"
+
+const selectAppliedQuery = "SELECT version, name, applied_at FROM schema_migrations ORDER BY version"
+
+var files = fstest.MapFS{
+	"0001_create_user.up.sql":   {Data: []byte("CREATE TABLE user (id BIGINT)")},
+	"0001_create_user.down.sql": {Data: []byte("DROP TABLE user")},
+	"0002_add_email.up.sql":     {Data: []byte("ALTER TABLE user ADD email TEXT")},
+	"0002_add_email.down.sql":   {Data: []byte("ALTER TABLE user DROP email")},
+	"0003_add_index.up.sql":     {Data: []byte("CREATE INDEX idx_email ON user (email)")},
+	"README.md":                 {Data: []byte("not a migration")},
+}
+
+func expectLocked(mock sqlmock.Sqlmock, applied *sqlmock.Rows) {
+	mock.ExpectPrepare("SELECT pg_try_advisory_lock($1)").ExpectQuery().WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(true))
+	mock.ExpectPrepare(createTableQuery).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare(selectAppliedQuery).ExpectQuery().WillReturnRows(applied)
+}
+
+func expectUnlocked(mock sqlmock.Sqlmock) {
+	mock.ExpectPrepare("SELECT pg_advisory_unlock($1)").ExpectExec().WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func Test_Migrator(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	m, err := migrate.New(db, files, migrate.DialectOpt(sqltool.DialectPostgres))
+	if err != nil {
+		t.Fatalf("error when read migrations, details: %v", err)
+	}
+	if len(m.Migrations()) != 3 {
+		t.Fatalf("expected 3 migrations but got %d", len(m.Migrations()))
+	}
+
+	appliedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	newRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"version", "name", "applied_at"})
+	}
+
+	// nothing is applied on empty migration table
+	expectLocked(mock, newRows())
+	expectUnlocked(mock)
+
+	statuses, err := m.Status(context.Background())
+	if err != nil || len(statuses) != 3 || statuses[0].Applied {
+		t.Fatalf("expected no migration applied but got %+v, details: %v", statuses, err)
+	}
+
+	// up to 2, version 1 is applied
+	expectLocked(mock, newRows().AddRow(1, "create_user", appliedAt))
+	mock.ExpectBegin()
+	mock.ExpectExec("ALTER TABLE user ADD email TEXT").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("INSERT INTO schema_migrations (version,name,applied_at) VALUES ($1,$2,$3)").
+		ExpectExec().
+		WithArgs(2, "add_email", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectUnlocked(mock)
+
+	done, err := m.UpTo(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("error when migrate up to 2, details: %v", err)
+	}
+	if len(done) != 1 || done[0] != 2 {
+		t.Fatalf("expected version 2 applied but got %v", done)
+	}
+
+	// failed migration is rolled back
+	expectLocked(mock, newRows().AddRow(1, "create_user", appliedAt).AddRow(2, "add_email", appliedAt))
+	mock.ExpectBegin()
+	mock.ExpectExec("CREATE INDEX idx_email ON user (email)").WillReturnError(errors.New("syntax error"))
+	mock.ExpectRollback()
+	expectUnlocked(mock)
+
+	_, err = m.Up(context.Background())
+	if err == nil {
+		t.Fatalf("expected error when migration failed")
+	}
+
+	// status
+	expectLocked(mock, newRows().AddRow(1, "create_user", appliedAt).AddRow(2, "add_email", appliedAt).AddRow(9, "removed", appliedAt))
+	expectUnlocked(mock)
+
+	statuses, err = m.Status(context.Background())
+	if err != nil {
+		t.Fatalf("error when get status, details: %v", err)
+	}
+	if len(statuses) != 4 || !statuses[1].Applied || !statuses[1].AppliedAt.Equal(appliedAt) || statuses[2].Applied || !statuses[3].Missing {
+		t.Fatalf("unexpected statuses %+v", statuses)
+	}
+
+	// down 1
+	expectLocked(mock, newRows().AddRow(1, "create_user", appliedAt).AddRow(2, "add_email", appliedAt))
+	mock.ExpectBegin()
+	mock.ExpectExec("ALTER TABLE user DROP email").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM schema_migrations WHERE version = $1").
+		ExpectExec().
+		WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectUnlocked(mock)
+
+	done, err = m.Down(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("error when migrate down, details: %v", err)
+	}
+	if len(done) != 1 || done[0] != 2 {
+		t.Fatalf("expected version 2 rolled back but got %v", done)
+	}
+
+	_, err = m.Down(context.Background(), -1)
+	if err == nil {
+		t.Fatalf("expected error when roll back negative number of migrations")
+	}
+
+	_, err = m.UpTo(context.Background(), 7)
+	if !errors.Is(err, migrate.ErrUnknownVersion) {
+		t.Fatalf("expected ErrUnknownVersion but got %v", err)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unfulfilled expectations, details: %v", err)
+	}
+}
+
+func Test_Migrator_MySQL(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	m, err := migrate.New(db, files)
+	if err != nil {
+		t.Fatalf("error when read migrations, details: %v", err)
+	}
+
+	appliedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	expectMySQLLocked := func(applied *sqlmock.Rows) {
+		mock.ExpectPrepare("SELECT GET_LOCK(?, ?)").
+			ExpectQuery().
+			WithArgs("schema_migrations", 60).
+			WillReturnRows(sqlmock.NewRows([]string{"GET_LOCK"}).AddRow(1))
+		mock.ExpectPrepare("CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, name VARCHAR(255) NOT NULL, applied_at DATETIME(6) NOT NULL)").
+			ExpectExec().
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectPrepare(selectAppliedQuery).ExpectQuery().WillReturnRows(applied)
+	}
+	expectMySQLUnlocked := func() {
+		mock.ExpectPrepare("SELECT RELEASE_LOCK(?)").
+			ExpectExec().
+			WithArgs("schema_migrations").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	// up to 2, migrations run without transaction
+	expectMySQLLocked(sqlmock.NewRows([]string{"version", "name", "applied_at"}))
+	mock.ExpectExec("CREATE TABLE user (id BIGINT)").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("INSERT INTO schema_migrations (version,name,applied_at) VALUES (?,?,?)").
+		ExpectExec().
+		WithArgs(1, "create_user", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("ALTER TABLE user ADD email TEXT").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("INSERT INTO schema_migrations (version,name,applied_at) VALUES (?,?,?)").
+		ExpectExec().
+		WithArgs(2, "add_email", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectMySQLUnlocked()
+
+	done, err := m.UpTo(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("error when migrate up to 2, details: %v", err)
+	}
+	if len(done) != 2 || done[0] != 1 || done[1] != 2 {
+		t.Fatalf("expected version 1 and 2 applied but got %v", done)
+	}
+
+	// down 1
+	expectMySQLLocked(sqlmock.NewRows([]string{"version", "name", "applied_at"}).
+		AddRow(1, "create_user", appliedAt).
+		AddRow(2, "add_email", appliedAt))
+	mock.ExpectExec("ALTER TABLE user DROP email").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM schema_migrations WHERE version = ?").
+		ExpectExec().
+		WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectMySQLUnlocked()
+
+	done, err = m.Down(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("error when migrate down, details: %v", err)
+	}
+	if len(done) != 1 || done[0] != 2 {
+		t.Fatalf("expected version 2 rolled back but got %v", done)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unfulfilled expectations, details: %v", err)
+	}
+}
+
+func Test_Migrator_MySQLLock(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	m, err := migrate.New(db, files, migrate.LockTimeoutOpt(5*time.Second))
+	if err != nil {
+		t.Fatalf("error when read migrations, details: %v", err)
+	}
+
+	mock.ExpectPrepare("SELECT GET_LOCK(?, ?)").
+		ExpectQuery().
+		WithArgs("schema_migrations", 5).
+		WillReturnRows(sqlmock.NewRows([]string{"GET_LOCK"}).AddRow(0))
+
+	_, err = m.Up(context.Background())
+	if !errors.Is(err, migrate.ErrLocked) {
+		t.Fatalf("expected ErrLocked but got %v", err)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unfulfilled expectations, details: %v", err)
+	}
+}
+
+func Test_Migrator_PostgresLock(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	tryLock := func(locked bool) {
+		mock.ExpectPrepare("SELECT pg_try_advisory_lock($1)").
+			ExpectQuery().
+			WithArgs(sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(locked))
+	}
+
+	// lock is retried until it is released by another runner
+	m, err := migrate.New(db, files, migrate.DialectOpt(sqltool.DialectPostgres))
+	if err != nil {
+		t.Fatalf("error when read migrations, details: %v", err)
+	}
+
+	tryLock(false)
+	tryLock(true)
+	mock.ExpectPrepare(createTableQuery).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare(selectAppliedQuery).ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"version", "name", "applied_at"}))
+	expectUnlocked(mock)
+
+	_, err = m.Status(context.Background())
+	if err != nil {
+		t.Fatalf("error when get status, details: %v", err)
+	}
+
+	// ErrLocked is returned after timeout
+	m, err = migrate.New(db, files, migrate.DialectOpt(sqltool.DialectPostgres), migrate.LockTimeoutOpt(0))
+	if err != nil {
+		t.Fatalf("error when read migrations, details: %v", err)
+	}
+
+	tryLock(false)
+
+	_, err = m.Up(context.Background())
+	if !errors.Is(err, migrate.ErrLocked) {
+		t.Fatalf("expected ErrLocked but got %v", err)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unfulfilled expectations, details: %v", err)
+	}
+}
+
+func Test_New_Invalid(t *testing.T) {
+	_, err := migrate.New(nil, fstest.MapFS{"0001_a.down.sql": {Data: []byte("DROP TABLE a")}})
+	if err == nil {
+		t.Fatalf("expected error when up migration is missing")
+	}
+
+	// empty up migration is allowed, e.g. placeholder of version
+	_, err = migrate.New(nil, fstest.MapFS{"0001_a.up.sql": {Data: []byte{}}})
+	if err != nil {
+		t.Fatalf("error when read empty up migration, details: %v", err)
+	}
+
+	_, err = migrate.New(nil, fstest.MapFS{
+		"0001_a.up.sql": {Data: []byte("CREATE TABLE a (id INT)")},
+		"0001_b.up.sql": {Data: []byte("CREATE TABLE b (id INT)")},
+	})
+	if err == nil {
+		t.Fatalf("expected error when version is duplicated")
+	}
+}
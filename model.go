@@ -0,0 +1,221 @@
+package sqltool
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/wizk3y/go-sqltool/internal"
+)
+
+// primaryKeyColumns -- columns identify a row of model, set by PrimaryKeyOpt or `db:",pk"` tag, default is serial column
+func (st *SQLTool) primaryKeyColumns() []string {
+	if len(st.primaryKeys) > 0 {
+		return st.primaryKeys
+	}
+
+	var columns []string
+	for _, column := range st.allColumns {
+		if _, ok := st.column2Tag[column]["pk"]; ok {
+			columns = append(columns, column)
+		}
+	}
+	if len(columns) > 0 {
+		return columns
+	}
+
+	return []string{st.serialColumn}
+}
+
+// primaryKeyWhere -- condition match row of model by primary key values, zero value is only rejected for serial column
+func (st *SQLTool) primaryKeyWhere(i interface{}) (squirrel.Eq, error) {
+	ve := reflect.Indirect(reflect.ValueOf(i))
+
+	where := squirrel.Eq{}
+	for _, column := range st.primaryKeyColumns() {
+		fieldName, ok := st.column2FieldName[column]
+		if !ok {
+			return nil, fmt.Errorf("primary key column %s is not found in model %s", column, st.modelName)
+		}
+
+		field := ve.FieldByName(fieldName)
+		if column == st.serialColumn && field.IsZero() {
+			return nil, fmt.Errorf("primary key column %s of model %s has zero value", column, st.modelName)
+		}
+		where[column] = field.Interface()
+	}
+
+	return where, nil
+}
+
+// UpdateByPK -- update row of model by primary key, only changed columns are updated when model is tracked and
+// nothing is executed when tracked model has no change.
+// Version column is checked and increased, *StaleObjectError is returned when model has been changed by another update.
+// Model is tracked again after update success when DirtyTrackingOpt is enabled or model has been tracked
+func (st *SQLTool) UpdateByPK(table string, i interface{}, opts ...sqlToolOpt) (sql.Result, error) {
+	err := st.PrepareUpdateE(i, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	m := st.GetUpdateMap()
+	if len(m) == 0 || st.dirtyColumns != nil && len(st.dirtyColumns) == 0 {
+		return driver.RowsAffected(0), nil
+	}
+
+	where, err := st.primaryKeyWhere(i)
+	if err != nil {
+		return nil, err
+	}
+	for column, value := range st.GetVersionWhere() {
+		where[column] = value
+	}
+
+	query, args, err := squirrel.Update(table).
+		SetMap(m).
+		Where(where).
+		PlaceholderFormat(st.dialect.placeholder()).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := st.Exec(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = st.CheckUpdateResult(i, res)
+	if err != nil {
+		return nil, err
+	}
+
+	// dirty columns are nil when model is not tracked
+	if st.dirtyTracking || st.dirtyColumns != nil {
+		st.track(reflect.ValueOf(i))
+	}
+	return res, nil
+}
+
+// FindByPK -- select row of model by primary key values set in model, soft deleted row is excluded unless
+// WithDeleted/OnlyDeleted is set
+func (st *SQLTool) FindByPK(table string, i interface{}, opts ...sqlToolOpt) error {
+	st.deletedScope = deletedScopeDefault
+	st.PrepareSelect(i, opts...)
+
+	where, err := st.primaryKeyWhere(i)
+	if err != nil {
+		return err
+	}
+
+	query, args, err := squirrel.Select(st.GetColumns()...).
+		From(table).
+		Where(where).
+		Where(st.softDeleteWhere()).
+		PlaceholderFormat(st.dialect.placeholder()).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	return st.SelectOne(i, query, args...)
+}
+
+// FindWhere -- select rows of model match condition to dest, dest must be pointer to slice of model.
+// Soft deleted rows are excluded unless WithDeleted/OnlyDeleted is set, empty slice is returned when no row matched
+func (st *SQLTool) FindWhere(table string, dest interface{}, cond squirrel.Sqlizer, opts ...sqlToolOpt) error {
+	slice, err := internal.GetBaseType(reflect.TypeOf(dest), reflect.Slice)
+	if err != nil {
+		return err
+	}
+
+	st.deletedScope = deletedScopeDefault
+	st.PrepareSelect(reflect.New(internal.Deref(slice.Elem())).Interface(), opts...)
+
+	builder := squirrel.Select(st.GetColumns()...).
+		From(table).
+		Where(st.softDeleteWhere()).
+		PlaceholderFormat(st.dialect.placeholder())
+	if cond != nil {
+		builder = builder.Where(cond)
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return err
+	}
+
+	return st.selectList(ScanModeAbort, true, dest, query, args...)
+}
+
+// DeleteByPK -- delete row of model by primary key, row is soft deleted when model has soft delete column.
+// Version column is checked, *StaleObjectError is returned when model has been changed by another update
+func (st *SQLTool) DeleteByPK(table string, i interface{}, opts ...sqlToolOpt) (sql.Result, error) {
+	err := st.PrepareDelete(i, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var query string
+	var args []interface{}
+	if m := st.GetSoftDeleteMap(); len(m) > 0 {
+		query, args, err = squirrel.Update(table).
+			SetMap(m).
+			Where(st.GetDeleteWhere()).
+			PlaceholderFormat(st.dialect.placeholder()).
+			ToSql()
+	} else {
+		query, args, err = squirrel.Delete(table).
+			Where(st.GetDeleteWhere()).
+			PlaceholderFormat(st.dialect.placeholder()).
+			ToSql()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := st.Exec(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = st.CheckUpdateResult(i, res)
+	if err != nil {
+		return nil, err
+	}
+
+	st.setSoftDeleteField(i, st.deleteTime)
+	st.Untrack(i)
+	return res, nil
+}
+
+// HardDelete -- delete row of model by primary key, ignore soft delete column
+func (st *SQLTool) HardDelete(table string, i interface{}, opts ...sqlToolOpt) (sql.Result, error) {
+	err := st.PrepareDelete(i, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, err := squirrel.Delete(table).
+		Where(st.deleteWhere).
+		PlaceholderFormat(st.dialect.placeholder()).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := st.Exec(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = st.CheckUpdateResult(i, res)
+	if err != nil {
+		return nil, err
+	}
+
+	st.Untrack(i)
+	return res, nil
+}
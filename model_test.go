@@ -0,0 +1,206 @@
+package sqltool_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Masterminds/squirrel"
+	"github.com/wizk3y/go-sqltool"
+)
+
+type modelTestUser struct {
+	ID        int64  `json:"id"`
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
+	Username  string `json:"username"`
+	Pass      string `json:"pass"`
+}
+
+func Test_SQLTool_DirtyTracking(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectPrepare("SELECT id, username, pass FROM user WHERE id = ?").
+		ExpectQuery().
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "pass"}).
+			AddRow(1, "sample", "sample"))
+	mock.ExpectPrepare("UPDATE user SET pass = ?, updated_at = ? WHERE id = ?").
+		ExpectExec().
+		WithArgs("changed", sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// real code
+	sqlTool := sqltool.NewTool(context.Background(), db)
+
+	var user modelTestUser
+	sqlTool.PrepareSelect(&user,
+		sqltool.DateTimeColumnsOpt([]string{"created_at", "updated_at"}),
+		sqltool.AutoCreateDateTimeColumnsOpt([]string{"created_at"}),
+		sqltool.AutoUpdateDateTimeColumnsOpt([]string{"updated_at"}),
+		sqltool.DirtyTrackingOpt(true),
+	)
+	err = sqlTool.SelectOne(&user, "SELECT id, username, pass FROM user WHERE id = ?", 1)
+	if err != nil {
+		t.Fatalf("error when execute select one, details: %v", err)
+	}
+
+	user.Pass = "changed"
+	_, err = sqlTool.UpdateByPK("user", &user)
+	if err != nil {
+		t.Fatalf("error when update by primary key, details: %v", err)
+	}
+
+	// nothing changed after update, no query is executed
+	res, err := sqlTool.UpdateByPK("user", &user)
+	if err != nil {
+		t.Fatalf("error when update unchanged model, details: %v", err)
+	}
+	if affected, _ := res.RowsAffected(); affected != 0 {
+		t.Fatalf("expected no row affected, got %d", affected)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unfulfilled expectations, details: %v", err)
+	}
+}
+
+type modelTestDocument struct {
+	ID      int64  `json:"id"`
+	Title   string `json:"title"`
+	Version int64  `json:"version" db:",version"`
+}
+
+func Test_SQLTool_OptimisticLocking(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	query := "UPDATE document SET title = ?, version = version + 1 WHERE id = ? AND version = ?"
+	mock.ExpectPrepare(query).
+		ExpectExec().
+		WithArgs("first", 1, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare(query).
+		ExpectExec().
+		WithArgs("second", 1, 4).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// real code
+	sqlTool := sqltool.NewTool(context.Background(), db)
+	doc := modelTestDocument{ID: 1, Title: "first", Version: 3}
+
+	_, err = sqlTool.UpdateByPK("document", &doc)
+	if err != nil {
+		t.Fatalf("error when update by primary key, details: %v", err)
+	}
+	if doc.Version != 4 {
+		t.Fatalf("expected version 4, got %d", doc.Version)
+	}
+
+	doc.Title = "second"
+	_, err = sqlTool.UpdateByPK("document", &doc)
+	if !errors.Is(err, sqltool.ErrStaleObject) {
+		t.Fatalf("expected ErrStaleObject, details: %v", err)
+	}
+}
+
+type modelTestMembership struct {
+	GroupID int64  `json:"group_id" db:",pk"`
+	UserID  int64  `json:"user_id" db:",pk"`
+	Role    string `json:"role"`
+}
+
+func Test_SQLTool_CompositePrimaryKey(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectPrepare("INSERT INTO membership (group_id,user_id,role) VALUES (?,?,?)").
+		ExpectExec().
+		WithArgs(1, 2, "member").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("UPDATE membership SET role = ? WHERE group_id = ? AND user_id = ?").
+		ExpectExec().
+		WithArgs("admin", 1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("UPDATE membership SET role = ? WHERE group_id = ? AND user_id = ?").
+		ExpectExec().
+		WithArgs("owner", 0, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// real code
+	sqlTool := sqltool.NewTool(context.Background(), db)
+	membership := modelTestMembership{GroupID: 1, UserID: 2, Role: "member"}
+
+	sqlTool.PrepareInsert(&membership)
+	query, args, err := squirrel.Insert("membership").
+		Columns(sqlTool.GetColumns()...).
+		Values(sqlTool.GetInsertValues()...).
+		ToSql()
+	if err != nil {
+		t.Fatalf("error when build query")
+	}
+
+	_, err = sqlTool.Exec(query, args...)
+	if err != nil {
+		t.Fatalf("error when execute insert query, details: %v", err)
+	}
+
+	membership.Role = "admin"
+	_, err = sqlTool.UpdateByPK("membership", &membership)
+	if err != nil {
+		t.Fatalf("error when update by primary key, details: %v", err)
+	}
+
+	// zero value is allowed for primary key column which is not serial column
+	_, err = sqlTool.UpdateByPK("membership", &modelTestMembership{GroupID: 0, UserID: 3, Role: "owner"})
+	if err != nil {
+		t.Fatalf("error when update by primary key has zero column, details: %v", err)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unfulfilled expectations, details: %v", err)
+	}
+}
+
+func Test_SQLTool_UpdateWithoutTracking(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	// model is not tracked, every update keep all columns
+	query := "UPDATE user SET created_at = ?, pass = ?, updated_at = ?, username = ? WHERE id = ?"
+	for i := 0; i < 2; i++ {
+		mock.ExpectPrepare(query).
+			ExpectExec().
+			WithArgs(1, "sample", 2, "sample", 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	// real code
+	sqlTool := sqltool.NewTool(context.Background(), db)
+	user := modelTestUser{ID: 1, CreatedAt: 1, UpdatedAt: 2, Username: "sample", Pass: "sample"}
+
+	for i := 0; i < 2; i++ {
+		_, err = sqlTool.UpdateByPK("user", &user)
+		if err != nil {
+			t.Fatalf("error when update by primary key, details: %v", err)
+		}
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unfulfilled expectations, details: %v", err)
+	}
+}
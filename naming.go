@@ -0,0 +1,38 @@
+package sqltool
+
+import (
+	"reflect"
+
+	"github.com/wizk3y/go-sqltool/internal"
+)
+
+// NamingStrategy -- name column of exported field without json tag, set by NamingStrategyOpt
+type NamingStrategy func(fieldName string) string
+
+var (
+	// SnakeCaseNaming -- UserID => user_id
+	SnakeCaseNaming NamingStrategy = internal.SnakeCase
+	// CamelCaseNaming -- UserID => userID
+	CamelCaseNaming NamingStrategy = internal.CamelCase
+	// IdentityNaming -- UserID => UserID
+	IdentityNaming NamingStrategy = func(fieldName string) string { return fieldName }
+)
+
+// columnName -- column of field by json tag, exported field without json tag is named by naming strategy when it is set
+func (st *SQLTool) columnName(f reflect.StructField) (string, bool) {
+	tag, ok := f.Tag.Lookup("json")
+	if !ok {
+		if st.namingStrategy == nil || len(f.PkgPath) > 0 {
+			return "", false
+		}
+
+		return st.namingStrategy(f.Name), true
+	}
+
+	jsonTags := internal.TrimedSpaceStringSlice(tag, ",")
+	if len(jsonTags) == 0 || jsonTags[0] == "-" {
+		return "", false
+	}
+
+	return jsonTags[0], true
+}
@@ -0,0 +1,71 @@
+package sqltool_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/wizk3y/go-sqltool"
+)
+
+type namingTestUser struct {
+	ID         int64
+	UserName   string
+	HTTPStatus int64
+	Email      string `json:"mail"`
+	Secret     string `json:"-"`
+	note       string
+}
+
+func Test_SQLTool_NamingStrategy(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	sqlTool := sqltool.NewTool(context.Background(), db)
+
+	// default behavior, field without json tag is skipped
+	sqlTool.PrepareSelect(&namingTestUser{})
+	if !reflect.DeepEqual(sqlTool.GetColumns(), []string{"mail"}) {
+		t.Fatalf("expected only tagged columns by default but got %v", sqlTool.GetColumns())
+	}
+
+	cases := []struct {
+		strategy sqltool.NamingStrategy
+		expected []string
+	}{
+		{sqltool.SnakeCaseNaming, []string{"id", "user_name", "http_status", "mail"}},
+		{sqltool.CamelCaseNaming, []string{"id", "userName", "httpStatus", "mail"}},
+		{sqltool.IdentityNaming, []string{"ID", "UserName", "HTTPStatus", "mail"}},
+		{strings.ToUpper, []string{"ID", "USERNAME", "HTTPSTATUS", "mail"}},
+	}
+	for _, c := range cases {
+		sqlTool.PrepareSelect(&namingTestUser{}, sqltool.NamingStrategyOpt(c.strategy))
+		if !reflect.DeepEqual(sqlTool.GetColumns(), c.expected) {
+			t.Fatalf("expected columns %v but got %v", c.expected, sqlTool.GetColumns())
+		}
+	}
+
+	mock.ExpectPrepare("SELECT id, user_name, http_status, mail FROM user").
+		ExpectQuery().
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_name", "http_status", "mail"}).AddRow(1, "alice", 200, "a@b.c"))
+
+	sqlTool.PrepareSelect(&namingTestUser{}, sqltool.NamingStrategyOpt(sqltool.SnakeCaseNaming))
+
+	var user namingTestUser
+	err = sqlTool.SelectOne(&user, "SELECT "+strings.Join(sqlTool.GetColumns(), ", ")+" FROM user")
+	if err != nil {
+		t.Fatalf("error when select with naming strategy, details: %v", err)
+	}
+	if user.ID != 1 || user.UserName != "alice" || user.HTTPStatus != 200 || user.Email != "a@b.c" {
+		t.Fatalf("unexpected user %+v", user)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unfulfilled expectations, details: %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package sqltool
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"time"
+
+	"github.com/wizk3y/go-sqltool/internal"
+)
+
+// nestedColumnSeparator -- separate prefix of nested struct and column, e.g. user__id
+const nestedColumnSeparator = "__"
+
+// isNestedField -- struct field named by db tag is mapped to prefixed columns, `db:"user,json"` keep JSON blob behavior.
+// Field with codec, time.Time and driver.Valuer/sql.Scanner are stored as one column
+func isNestedField(f reflect.StructField, dbName string, dbFlags map[string]string) bool {
+	if len(dbName) == 0 || dbName == "-" {
+		return false
+	}
+
+	if _, ok := dbFlags["json"]; ok {
+		return false
+	}
+	if _, ok := dbFlags["codec"]; ok {
+		return false
+	}
+
+	t := internal.Deref(f.Type)
+	if t.Kind() != reflect.Struct || t == timeType {
+		return false
+	}
+
+	ptr := reflect.PtrTo(t)
+	return !t.Implements(valuerType) && !ptr.Implements(valuerType) && !ptr.Implements(scannerType)
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+)
+
+// parseNestedColumns -- add columns of nested struct with prefix, columns are only used to scan SELECT result
+func (st *SQLTool) parseNestedColumns(f reflect.StructField, prefix string, index []int, groups []string) {
+	if f.Type.Kind() == reflect.Ptr {
+		groups = append(append([]string{}, groups...), prefix)
+	}
+
+	t := internal.Deref(f.Type)
+	for i := 0; i < t.NumField(); i++ {
+		sub := t.Field(i)
+		if len(sub.PkgPath) > 0 {
+			continue
+		}
+
+		subIndex := append(append([]int{}, index...), sub.Index...)
+		dbName, dbFlags := parseDBTag(sub.Tag.Get("db"))
+		if isNestedField(sub, dbName, dbFlags) {
+			st.parseNestedColumns(sub, prefix+nestedColumnSeparator+dbName, subIndex, groups)
+			continue
+		}
+
+		name, ok := st.columnName(sub)
+		if !ok {
+			continue
+		}
+
+		column := prefix + nestedColumnSeparator + name
+		st.column2Type[column] = sub.Type
+		st.column2FieldIndex[column] = subIndex
+		st.column2NestedGroups[column] = groups
+		if st.isIgnoreColumn(column) {
+			continue
+		}
+		st.columns = append(st.columns, column)
+	}
+}
+
+// nullNestedGroups -- prefixes of nested pointer which all columns are NULL
+func (st *SQLTool) nullNestedGroups(columns []string, values []interface{}) map[string]bool {
+	var groups map[string]bool
+
+	for index, column := range columns {
+		for _, group := range st.column2NestedGroups[column] {
+			if groups == nil {
+				groups = make(map[string]bool)
+			}
+
+			isNull := isNullScanValue(values[index])
+			if current, ok := groups[group]; ok {
+				groups[group] = current && isNull
+			} else {
+				groups[group] = isNull
+			}
+		}
+	}
+
+	return groups
+}
+
+func (st *SQLTool) inNullGroup(column string, nullGroups map[string]bool) bool {
+	for _, group := range st.column2NestedGroups[column] {
+		if nullGroups[group] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isNullScanValue(value interface{}) bool {
+	switch v := value.(type) {
+	case *sql.NullString:
+		return !v.Valid
+	case *sql.NullBool:
+		return !v.Valid
+	case *sql.NullFloat64:
+		return !v.Valid
+	case *sql.NullInt64:
+		return !v.Valid
+	case *nullTime:
+		return !v.Valid
+	case *sql.RawBytes:
+		return *v == nil
+	}
+
+	return false
+}
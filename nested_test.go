@@ -0,0 +1,75 @@
+package sqltool_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/wizk3y/go-sqltool"
+)
+
+type nestedTestOrder struct {
+	ID      int64             `json:"id"`
+	Amount  int64             `json:"amount"`
+	User    nestedTestUser    `db:"user"`
+	Coupon  *nestedTestCoupon `db:"coupon"`
+	Payload *nestedTestUser   `json:"payload" db:"payload,json"`
+}
+
+type nestedTestUser struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type nestedTestCoupon struct {
+	Code     string `json:"code"`
+	Discount int64  `json:"discount"`
+}
+
+func Test_SQLTool_NestedStruct(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	query := "SELECT o.id, o.amount, u.id AS `user.id`, u.name AS `user.name`, c.code AS coupon__code, c.discount AS coupon__discount, o.payload FROM orders o JOIN user u ON u.id = o.user_id LEFT JOIN coupon c ON c.order_id = o.id"
+	mock.ExpectPrepare(query).
+		ExpectQuery().
+		WillReturnRows(sqlmock.NewRows([]string{"id", "amount", "user.id", "user.name", "coupon__code", "coupon__discount", "payload"}).
+			AddRow(1, 100, 10, "alice", "SALE", 5, `{"id":10,"name":"alice"}`).
+			AddRow(2, 200, 11, "bob", nil, nil, nil))
+
+	// real code
+	sqlTool := sqltool.NewTool(context.Background(), db)
+	sqlTool.PrepareSelect(&nestedTestOrder{})
+
+	var orders []nestedTestOrder
+	err = sqlTool.SelectAll(&orders, query)
+	if err != nil {
+		t.Fatalf("error when select nested struct, details: %v", err)
+	}
+
+	if len(orders) != 2 || orders[0].User.Name != "alice" || orders[1].User.ID != 11 {
+		t.Fatalf("unexpected nested user %+v", orders)
+	}
+	if orders[0].Coupon == nil || orders[0].Coupon.Code != "SALE" || orders[0].Coupon.Discount != 5 {
+		t.Fatalf("unexpected nested coupon %+v", orders[0].Coupon)
+	}
+	if orders[1].Coupon != nil {
+		t.Fatalf("expected nil coupon when joined columns are NULL but got %+v", orders[1].Coupon)
+	}
+	if orders[0].Payload == nil || orders[0].Payload.Name != "alice" {
+		t.Fatalf("unexpected JSON payload %+v", orders[0].Payload)
+	}
+
+	// nested columns are only used by SELECT
+	sqlTool.PrepareInsert(&orders[0])
+	if len(sqlTool.GetColumns()) != 2 {
+		t.Fatalf("expected insert columns [amount payload] with serial id ignored but got %v", sqlTool.GetColumns())
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unfulfilled expectations, details: %v", err)
+	}
+}
@@ -0,0 +1,316 @@
+package sqltool
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/wizk3y/go-sqltool/internal"
+)
+
+// ErrInvalidCursor -- returned by PaginateKeyset when cursor is malformed or not match keyset columns
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// OffsetPage -- request of offset pagination, Page start from 1
+type OffsetPage struct {
+	Page    uint64
+	PerPage uint64
+}
+
+// PageInfo -- result of offset pagination
+type PageInfo struct {
+	Page       uint64
+	PerPage    uint64
+	Total      uint64
+	TotalPages uint64
+	HasNext    bool
+	HasPrev    bool
+}
+
+// Paginate -- do offset pagination, fill items of page to dest and count total rows match builder.
+// builder must not have LIMIT/OFFSET, dest must be pointer to slice of model prepared by PrepareSelect
+func (st *SQLTool) Paginate(dest interface{}, builder squirrel.SelectBuilder, page OffsetPage) (PageInfo, error) {
+	if page.Page < 1 {
+		page.Page = 1
+	}
+	if page.PerPage < 1 {
+		return PageInfo{}, errors.New("per page must be greater than 0")
+	}
+
+	info := PageInfo{
+		Page:    page.Page,
+		PerPage: page.PerPage,
+	}
+
+	query, args, err := builder.RemoveLimit().RemoveOffset().ToSql()
+	if err != nil {
+		return info, err
+	}
+
+	var total int64
+	err = st.SelectScalar(&total, "SELECT COUNT(*) FROM ("+query+") AS sqltool_count", args...)
+	if err != nil {
+		return info, err
+	}
+
+	info.Total = uint64(total)
+	info.TotalPages = (info.Total + page.PerPage - 1) / page.PerPage
+	info.HasNext = page.Page < info.TotalPages
+	info.HasPrev = page.Page > 1
+
+	query, args, err = builder.Limit(page.PerPage).Offset((page.Page - 1) * page.PerPage).ToSql()
+	if err != nil {
+		return info, err
+	}
+
+	return info, st.selectList(ScanModeAbort, true, dest, query, args...)
+}
+
+// KeysetColumn -- column used to order keyset pagination
+type KeysetColumn struct {
+	Column string
+	Desc   bool
+}
+
+// KeysetPage -- request of keyset pagination, Cursor is empty for first page.
+// Primary key columns are appended to Columns if missing, so rows having same values of other columns are not skipped
+type KeysetPage struct {
+	Columns []KeysetColumn
+	Limit   uint64
+	Cursor  string
+}
+
+// KeysetInfo -- result of keyset pagination, cursor is empty when there is no page in that direction
+type KeysetInfo struct {
+	NextCursor string
+	PrevCursor string
+}
+
+type keysetCursor struct {
+	Backward bool              `json:"b,omitempty"`
+	Values   []json.RawMessage `json:"v"`
+}
+
+// PaginateKeyset -- do keyset pagination, fill items of page to dest.
+// builder must not have ORDER BY/LIMIT, dest must be pointer to slice of model prepared by PrepareSelect
+func (st *SQLTool) PaginateKeyset(dest interface{}, builder squirrel.SelectBuilder, page KeysetPage) (KeysetInfo, error) {
+	var info KeysetInfo
+
+	if page.Limit < 1 {
+		return info, errors.New("limit must be greater than 0")
+	}
+
+	direct, err := keysetTarget(dest)
+	if err != nil {
+		return info, err
+	}
+
+	columns, err := st.keysetColumns(page.Columns)
+	if err != nil {
+		return info, err
+	}
+
+	var cursor keysetCursor
+	if len(page.Cursor) > 0 {
+		cursor, err = decodeKeysetCursor(page.Cursor, len(columns))
+		if err != nil {
+			return info, err
+		}
+
+		cond, err := st.keysetCondition(columns, cursor)
+		if err != nil {
+			return info, err
+		}
+		builder = builder.Where(cond)
+	}
+
+	orderBys := make([]string, 0, len(columns))
+	for _, c := range columns {
+		if c.Desc != cursor.Backward {
+			orderBys = append(orderBys, c.Column+" DESC")
+		} else {
+			orderBys = append(orderBys, c.Column+" ASC")
+		}
+	}
+
+	query, args, err := builder.OrderBy(orderBys...).Limit(page.Limit + 1).ToSql()
+	if err != nil {
+		return info, err
+	}
+
+	direct.Set(reflect.MakeSlice(direct.Type(), 0, 0))
+	err = st.selectList(ScanModeAbort, true, dest, query, args...)
+	if err != nil {
+		return info, err
+	}
+
+	hasMore := uint64(direct.Len()) > page.Limit
+	if hasMore {
+		direct.Set(direct.Slice(0, int(page.Limit)))
+	}
+	if cursor.Backward {
+		swap := reflect.Swapper(direct.Interface())
+		for i, j := 0, direct.Len()-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+
+	if direct.Len() == 0 {
+		return info, nil
+	}
+
+	if hasMore || cursor.Backward {
+		info.NextCursor, err = st.encodeKeysetCursor(columns, direct.Index(direct.Len()-1), false)
+		if err != nil {
+			return info, err
+		}
+	}
+	if len(page.Cursor) > 0 && (hasMore || !cursor.Backward) {
+		info.PrevCursor, err = st.encodeKeysetCursor(columns, direct.Index(0), true)
+		if err != nil {
+			return info, err
+		}
+	}
+
+	return info, nil
+}
+
+func keysetTarget(dest interface{}) (reflect.Value, error) {
+	value := reflect.ValueOf(dest)
+	if value.Kind() != reflect.Ptr {
+		return reflect.Value{}, errors.New("must pass a pointer, not a value, to StructScan destination")
+	}
+	if value.IsNil() {
+		return reflect.Value{}, errors.New("nil pointer passed to StructScan destination")
+	}
+
+	_, err := internal.GetBaseType(value.Type(), reflect.Slice)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	return value.Elem(), nil
+}
+
+func (st *SQLTool) keysetColumns(columns []KeysetColumn) ([]KeysetColumn, error) {
+	used := make(map[string]bool)
+	for _, c := range columns {
+		if _, ok := st.column2FieldName[keysetFieldColumn(c.Column)]; !ok {
+			return nil, fmt.Errorf("keyset column %s is not found in model", c.Column)
+		}
+		used[keysetFieldColumn(c.Column)] = true
+	}
+
+	var desc bool
+	if len(columns) > 0 {
+		desc = columns[len(columns)-1].Desc
+	}
+
+	columns = append([]KeysetColumn{}, columns...)
+	for _, pk := range st.primaryKeyColumns() {
+		if _, ok := st.column2FieldName[pk]; ok && !used[pk] {
+			columns = append(columns, KeysetColumn{Column: pk, Desc: desc})
+		}
+	}
+
+	if len(columns) == 0 {
+		return nil, errors.New("keyset pagination require at least one column")
+	}
+
+	return columns, nil
+}
+
+// keysetFieldColumn -- column of model for keyset column, table qualifier is removed
+func keysetFieldColumn(column string) string {
+	if i := strings.LastIndex(column, "."); i >= 0 {
+		return column[i+1:]
+	}
+
+	return column
+}
+
+// keysetCondition -- build (c1 > v1) OR (c1 = v1 AND c2 > v2) ..., operator is reversed by direction of column and cursor
+func (st *SQLTool) keysetCondition(columns []KeysetColumn, cursor keysetCursor) (squirrel.Sqlizer, error) {
+	values := make([]interface{}, len(columns))
+	for index, c := range columns {
+		value, err := st.keysetValue(keysetFieldColumn(c.Column), cursor.Values[index])
+		if err != nil {
+			return nil, err
+		}
+		values[index] = value
+	}
+
+	or := squirrel.Or{}
+	for index, c := range columns {
+		and := squirrel.And{}
+		for prev := 0; prev < index; prev++ {
+			and = append(and, squirrel.Eq{columns[prev].Column: values[prev]})
+		}
+
+		if c.Desc != cursor.Backward {
+			and = append(and, squirrel.Lt{c.Column: values[index]})
+		} else {
+			and = append(and, squirrel.Gt{c.Column: values[index]})
+		}
+		or = append(or, and)
+	}
+
+	return or, nil
+}
+
+// keysetValue -- decode cursor value to type of model field, date/time value is converted back to time.Time
+func (st *SQLTool) keysetValue(column string, raw json.RawMessage) (interface{}, error) {
+	vType := st.column2Type[column]
+	value := reflect.New(vType)
+	err := json.Unmarshal(raw, value.Interface())
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if st.isDateTimeColumn(column) && vType.Kind() == reflect.Int64 {
+		return internal.GetTimeByUnit(value.Elem().Int(), st.dateTimeUnit), nil
+	}
+
+	return value.Elem().Interface(), nil
+}
+
+func (st *SQLTool) encodeKeysetCursor(columns []KeysetColumn, item reflect.Value, backward bool) (string, error) {
+	item = reflect.Indirect(item)
+
+	cursor := keysetCursor{Backward: backward}
+	for _, c := range columns {
+		fieldName := st.column2FieldName[keysetFieldColumn(c.Column)]
+		raw, err := json.Marshal(item.FieldByName(fieldName).Interface())
+		if err != nil {
+			return "", err
+		}
+		cursor.Values = append(cursor.Values, raw)
+	}
+
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeKeysetCursor(s string, columns int) (keysetCursor, error) {
+	var cursor keysetCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor, ErrInvalidCursor
+	}
+
+	err = json.Unmarshal(data, &cursor)
+	if err != nil || len(cursor.Values) != columns {
+		return cursor, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
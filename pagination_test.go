@@ -0,0 +1,106 @@
+package sqltool_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Masterminds/squirrel"
+	"github.com/wizk3y/go-sqltool"
+)
+
+type paginationTestPost struct {
+	ID    int64  `json:"id"`
+	Score int64  `json:"score"`
+	Title string `json:"title"`
+}
+
+func Test_SQLTool_Paginate(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectPrepare("SELECT COUNT(*) FROM (SELECT id, score, title FROM post WHERE score > ?) AS sqltool_count").
+		ExpectQuery().
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(5))
+	mock.ExpectPrepare("SELECT id, score, title FROM post WHERE score > ? LIMIT 2 OFFSET 2").
+		ExpectQuery().
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "score", "title"}).AddRow(3, 20, "c").AddRow(4, 20, "d"))
+
+	// real code
+	sqlTool := sqltool.NewTool(context.Background(), db)
+	sqlTool.PrepareSelect(&paginationTestPost{})
+
+	builder := squirrel.Select(sqlTool.GetColumns()...).From("post").Where(squirrel.Gt{"score": 10})
+
+	var posts []paginationTestPost
+	info, err := sqlTool.Paginate(&posts, builder, sqltool.OffsetPage{Page: 2, PerPage: 2})
+	if err != nil {
+		t.Fatalf("error when paginate, details: %v", err)
+	}
+	if info.Total != 5 || info.TotalPages != 3 || !info.HasNext || !info.HasPrev || len(posts) != 2 {
+		t.Fatalf("unexpected page info %+v, items: %d", info, len(posts))
+	}
+}
+
+func Test_SQLTool_PaginateKeyset(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	columns := []string{"id", "score", "title"}
+	mock.ExpectPrepare("SELECT id, score, title FROM post ORDER BY score DESC, id DESC LIMIT 3").
+		ExpectQuery().
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(9, 50, "a").AddRow(7, 40, "b").AddRow(8, 40, "c"))
+	mock.ExpectPrepare("SELECT id, score, title FROM post WHERE ((score < ?) OR (score = ? AND id < ?)) ORDER BY score DESC, id DESC LIMIT 3").
+		ExpectQuery().
+		WithArgs(40, 40, 7).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(8, 40, "c"))
+	mock.ExpectPrepare("SELECT id, score, title FROM post WHERE ((score > ?) OR (score = ? AND id > ?)) ORDER BY score ASC, id ASC LIMIT 3").
+		ExpectQuery().
+		WithArgs(40, 40, 8).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(7, 40, "b").AddRow(9, 50, "a"))
+
+	// real code
+	sqlTool := sqltool.NewTool(context.Background(), db)
+	sqlTool.PrepareSelect(&paginationTestPost{})
+
+	builder := squirrel.Select(sqlTool.GetColumns()...).From("post")
+	page := sqltool.KeysetPage{
+		Columns: []sqltool.KeysetColumn{{Column: "score", Desc: true}},
+		Limit:   2,
+	}
+
+	var posts []paginationTestPost
+	info, err := sqlTool.PaginateKeyset(&posts, builder, page)
+	if err != nil {
+		t.Fatalf("error when paginate first page, details: %v", err)
+	}
+	if len(posts) != 2 || len(info.NextCursor) == 0 || len(info.PrevCursor) != 0 {
+		t.Fatalf("unexpected first page %+v, items: %v", info, posts)
+	}
+
+	page.Cursor = info.NextCursor
+	info, err = sqlTool.PaginateKeyset(&posts, builder, page)
+	if err != nil {
+		t.Fatalf("error when paginate next page, details: %v", err)
+	}
+	if len(posts) != 1 || posts[0].ID != 8 || len(info.NextCursor) != 0 || len(info.PrevCursor) == 0 {
+		t.Fatalf("unexpected second page %+v, items: %v", info, posts)
+	}
+
+	page.Cursor = info.PrevCursor
+	info, err = sqlTool.PaginateKeyset(&posts, builder, page)
+	if err != nil {
+		t.Fatalf("error when paginate previous page, details: %v", err)
+	}
+	if len(posts) != 2 || posts[0].ID != 9 || posts[1].ID != 7 || len(info.NextCursor) == 0 || len(info.PrevCursor) != 0 {
+		t.Fatalf("unexpected previous page %+v, items: %v", info, posts)
+	}
+}
@@ -0,0 +1,242 @@
+package sqltool
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// PostgresArrayCodec -- slice of string/bool/int/uint/float or pointer to them to PostgreSQL array text format, e.g.
+// {1,2,NULL} and {"a","b \"c\""}. nil element is written as NULL, NULL element is read as nil or zero value.
+// Used by default for these slices with DialectPostgres
+var PostgresArrayCodec Codec = pgArrayCodec{}
+
+func init() {
+	RegisterCodec("pgarray", PostgresArrayCodec)
+}
+
+// isPostgresArrayType -- slice type can be encoded as PostgreSQL array, []byte is excluded
+func isPostgresArrayType(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice {
+		return false
+	}
+
+	elem := t.Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	} else if elem.Kind() == reflect.Uint8 {
+		return false
+	}
+
+	switch elem.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+type pgArrayCodec struct{}
+
+func (pgArrayCodec) Encode(v interface{}) ([]byte, error) {
+	value := reflect.Indirect(reflect.ValueOf(v))
+	if !isPostgresArrayType(value.Type()) {
+		return nil, fmt.Errorf("postgres array codec does not support %T", v)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for index := 0; index < value.Len(); index++ {
+		if index > 0 {
+			buf.WriteByte(',')
+		}
+
+		elem := value.Index(index)
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				buf.WriteString("NULL")
+				continue
+			}
+			elem = elem.Elem()
+		}
+
+		switch elem.Kind() {
+		case reflect.String:
+			buf.WriteByte('"')
+			for _, r := range elem.String() {
+				if r == '"' || r == '\\' {
+					buf.WriteByte('\\')
+				}
+				buf.WriteRune(r)
+			}
+			buf.WriteByte('"')
+		case reflect.Bool:
+			buf.WriteString(strconv.FormatBool(elem.Bool()))
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			buf.WriteString(strconv.FormatInt(elem.Int(), 10))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			buf.WriteString(strconv.FormatUint(elem.Uint(), 10))
+		case reflect.Float32:
+			buf.WriteString(strconv.FormatFloat(elem.Float(), 'g', -1, 32))
+		case reflect.Float64:
+			buf.WriteString(strconv.FormatFloat(elem.Float(), 'g', -1, 64))
+		}
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+func (pgArrayCodec) Decode(data []byte, v interface{}) error {
+	ptr := reflect.ValueOf(v)
+	if ptr.Kind() != reflect.Ptr || !isPostgresArrayType(ptr.Type().Elem()) {
+		return fmt.Errorf("postgres array codec does not support %T", v)
+	}
+
+	elements, err := parsePostgresArray(string(data))
+	if err != nil {
+		return err
+	}
+
+	sliceType := ptr.Type().Elem()
+	slice := reflect.MakeSlice(sliceType, len(elements), len(elements))
+	for index, element := range elements {
+		if element == nil {
+			continue
+		}
+
+		elem := slice.Index(index)
+		if elem.Kind() == reflect.Ptr {
+			elem.Set(reflect.New(sliceType.Elem().Elem()))
+			elem = elem.Elem()
+		}
+
+		err = setPostgresArrayElement(elem, *element)
+		if err != nil {
+			return fmt.Errorf("invalid element %d of postgres array: %v", index, err)
+		}
+	}
+	ptr.Elem().Set(slice)
+
+	return nil
+}
+
+func setPostgresArrayElement(elem reflect.Value, s string) error {
+	switch elem.Kind() {
+	case reflect.String:
+		elem.SetString(s)
+	case reflect.Bool:
+		switch strings.ToLower(s) {
+		case "t", "true":
+			elem.SetBool(true)
+		case "f", "false":
+			elem.SetBool(false)
+		default:
+			return fmt.Errorf("invalid bool %q", s)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, elem.Type().Bits())
+		if err != nil {
+			return err
+		}
+		elem.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, elem.Type().Bits())
+		if err != nil {
+			return err
+		}
+		elem.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, elem.Type().Bits())
+		if err != nil {
+			return err
+		}
+		elem.SetFloat(f)
+	}
+
+	return nil
+}
+
+// parsePostgresArray -- parse one-dimensional array in text format, nil is returned for NULL element
+func parsePostgresArray(s string) ([]*string, error) {
+	if len(s) < 2 || s[0] != '{' || s[len(s)-1] != '}' {
+		return nil, fmt.Errorf("invalid postgres array %q", s)
+	}
+
+	body := s[1 : len(s)-1]
+	elements := make([]*string, 0)
+	if len(strings.TrimSpace(body)) == 0 {
+		return elements, nil
+	}
+
+	for pos := 0; ; {
+		for pos < len(body) && body[pos] == ' ' {
+			pos++
+		}
+
+		var (
+			buf    strings.Builder
+			quoted bool
+		)
+		if pos < len(body) && body[pos] == '"' {
+			quoted = true
+			pos++
+			for {
+				if pos >= len(body) {
+					return nil, fmt.Errorf("unterminated quoted element in postgres array %q", s)
+				}
+				if body[pos] == '\\' && pos+1 < len(body) {
+					buf.WriteByte(body[pos+1])
+					pos += 2
+					continue
+				}
+				if body[pos] == '"' {
+					pos++
+					break
+				}
+				buf.WriteByte(body[pos])
+				pos++
+			}
+		} else {
+			for pos < len(body) && body[pos] != ',' {
+				if body[pos] == '{' || body[pos] == '"' {
+					return nil, errors.New("multidimensional postgres array is not supported")
+				}
+				if body[pos] == '\\' && pos+1 < len(body) {
+					pos++
+				}
+				buf.WriteByte(body[pos])
+				pos++
+			}
+		}
+
+		element := buf.String()
+		if !quoted {
+			element = strings.TrimSpace(element)
+		}
+		if !quoted && strings.EqualFold(element, "NULL") {
+			elements = append(elements, nil)
+		} else {
+			elements = append(elements, &element)
+		}
+
+		for pos < len(body) && body[pos] == ' ' {
+			pos++
+		}
+		if pos >= len(body) {
+			break
+		}
+		if body[pos] != ',' {
+			return nil, fmt.Errorf("invalid postgres array %q", s)
+		}
+		pos++
+	}
+
+	return elements, nil
+}
@@ -0,0 +1,84 @@
+package sqltool_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/wizk3y/go-sqltool"
+)
+
+type pgArrayTestPost struct {
+	ID      int64     `json:"id"`
+	Tags    []string  `json:"tags"`
+	Scores  []int64   `json:"scores"`
+	Ratings []*int64  `json:"ratings"`
+	Owners  []string  `json:"owners"`
+	Weights []float64 `json:"weights"`
+}
+
+func Test_SQLTool_PostgresArray(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	five := int64(5)
+
+	// encode
+	sqlTool := sqltool.NewTool(context.Background(), db)
+	sqlTool.PrepareInsert(&pgArrayTestPost{
+		Tags:    []string{"a b", `say "hi"`, `back\slash`, "NULL", ""},
+		Scores:  []int64{1, -2},
+		Ratings: []*int64{&five, nil},
+		Owners:  []string{},
+	}, sqltool.DialectOpt(sqltool.DialectPostgres))
+
+	expected := []interface{}{`{"a b","say \"hi\"","back\\slash","NULL",""}`, "{1,-2}", "{5,NULL}", "{}", nil}
+	if !reflect.DeepEqual(sqlTool.GetInsertValues(), expected) {
+		t.Fatalf("expected values %v but got %v", expected, sqlTool.GetInsertValues())
+	}
+
+	// decode
+	mock.ExpectPrepare("SELECT id, tags, scores, ratings, owners, weights FROM post WHERE id = $1").
+		ExpectQuery().
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tags", "scores", "ratings", "owners", "weights"}).
+			AddRow(1, `{"a b","say \"hi\"","back\\slash","NULL",plain}`, "{1,-2}", "{5,NULL}", "{}", "{1.5, 2}"))
+
+	sqlTool.PrepareSelect(&pgArrayTestPost{})
+
+	var post pgArrayTestPost
+	err = sqlTool.SelectOne(&post, "SELECT id, tags, scores, ratings, owners, weights FROM post WHERE id = $1", 1)
+	if err != nil {
+		t.Fatalf("error when select postgres array, details: %v", err)
+	}
+
+	if !reflect.DeepEqual(post.Tags, []string{"a b", `say "hi"`, `back\slash`, "NULL", "plain"}) {
+		t.Fatalf("unexpected tags %q", post.Tags)
+	}
+	if !reflect.DeepEqual(post.Scores, []int64{1, -2}) || !reflect.DeepEqual(post.Weights, []float64{1.5, 2}) {
+		t.Fatalf("unexpected scores %v or weights %v", post.Scores, post.Weights)
+	}
+	if len(post.Ratings) != 2 || *post.Ratings[0] != 5 || post.Ratings[1] != nil {
+		t.Fatalf("unexpected ratings %v", post.Ratings)
+	}
+	if post.Owners == nil || len(post.Owners) != 0 {
+		t.Fatalf("expected empty owners but got %v", post.Owners)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unfulfilled expectations, details: %v", err)
+	}
+}
+
+func Test_PostgresArrayCodec_Invalid(t *testing.T) {
+	var list []int64
+	for _, s := range []string{"1,2", `{"a`, "{{1,2},{3,4}}", "{a}"} {
+		if err := sqltool.PostgresArrayCodec.Decode([]byte(s), &list); err == nil {
+			t.Fatalf("expected error when decode %s", s)
+		}
+	}
+}
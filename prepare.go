@@ -1,88 +1,110 @@
 package sqltool
 
 import (
-	"encoding/json"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
+	"github.com/Masterminds/squirrel"
 	"github.com/wizk3y/go-sqltool/internal"
 )
 
-// PrepareInsert -- parse model struct and values support INSERT INTO command
+// PrepareInsert -- parse model struct and values support INSERT INTO command. Error when value can not be prepared is
+// returned by next Exec, use PrepareInsertE to get error immediately
 func (st *SQLTool) PrepareInsert(i interface{}, opts ...sqlToolOpt) {
-	if st.actionType != insertAction {
-		st.actionType = insertAction
-	}
-
-	var (
-		iPkgPath   = reflect.TypeOf(i).PkgPath()
-		iName      = reflect.TypeOf(i).String()
-		needUpdate bool
-	)
-	if st.modelPkgPath != iPkgPath || st.modelName != iName {
-		st.modelPkgPath = iPkgPath
-		st.modelName = iName
-		needUpdate = true
+	err := st.PrepareInsertE(i, opts...)
+	if err != nil {
+		st.prepareErr = err
 	}
+}
 
-	// apply opts
-	for _, o := range opts {
-		needUpdate = o.Apply(st) || needUpdate
+// PrepareInsertE -- same as PrepareInsert, error is returned when value can not be prepared, e.g. invalid enum value,
+// or *ValidationError when ValidateOpt is enabled and model is invalid
+func (st *SQLTool) PrepareInsertE(i interface{}, opts ...sqlToolOpt) (err error) {
+	st.prepare(insertAction, i, opts)
+	if st.validate {
+		err = st.validateModel(i)
+		if err != nil {
+			return
+		}
 	}
 
-	// parse columns
-	if needUpdate {
-		st.parseColumns(i)
-	}
-	st.values = st.PrepareValues(i)
+	st.values, err = st.PrepareValuesE(i)
+	return
 }
 
 // PrepareSelect -- parse model struct and values support SELECT command
 func (st *SQLTool) PrepareSelect(i interface{}, opts ...sqlToolOpt) {
-	if st.actionType != selectAction {
-		st.actionType = selectAction
-	}
+	st.prepare(selectAction, i, opts)
+}
 
-	var (
-		iPkgPath   = reflect.TypeOf(i).PkgPath()
-		iName      = reflect.TypeOf(i).String()
-		needUpdate bool
-	)
-	if st.modelPkgPath != iPkgPath || st.modelName != iName {
-		st.modelPkgPath = iPkgPath
-		st.modelName = iName
-		needUpdate = true
+// PrepareUpdate -- parse model struct and values support UPDATE command, only changed columns are kept when model is tracked.
+// Error when value can not be prepared is returned by next Exec, use PrepareUpdateE to get error immediately
+func (st *SQLTool) PrepareUpdate(i interface{}, opts ...sqlToolOpt) {
+	err := st.PrepareUpdateE(i, opts...)
+	if err != nil {
+		st.prepareErr = err
 	}
+}
 
-	// apply opts
-	for _, o := range opts {
-		needUpdate = o.Apply(st) || needUpdate
+// PrepareUpdateE -- same as PrepareUpdate, error is returned when value can not be prepared, e.g. invalid enum value,
+// or *ValidationError when ValidateOpt is enabled and model is invalid
+func (st *SQLTool) PrepareUpdateE(i interface{}, opts ...sqlToolOpt) (err error) {
+	st.prepare(updateAction, i, opts)
+	if st.validate {
+		err = st.validateModel(i)
+		if err != nil {
+			return
+		}
 	}
 
-	// parse columns
-	if needUpdate {
-		st.parseColumns(i)
-	}
+	st.values, err = st.PrepareValuesE(i)
+	st.dirtyColumns = st.changedColumns(i)
+	st.prepareVersion(i)
+	return
 }
 
-// PrepareUpdate -- parse model struct and values support UPDATE command
-func (st *SQLTool) PrepareUpdate(i interface{}, opts ...sqlToolOpt) {
-	if st.actionType != updateAction {
-		st.actionType = updateAction
+// PrepareDelete -- parse model struct and primary key values support DELETE command, use GetDeleteWhere to build condition
+// and GetSoftDeleteMap to build UPDATE instead when model has soft delete column
+func (st *SQLTool) PrepareDelete(i interface{}, opts ...sqlToolOpt) error {
+	st.prepare(deleteAction, i, opts)
+	st.prepareVersion(i)
+	st.deleteTime = time.Now()
+
+	where, err := st.primaryKeyWhere(i)
+	if err != nil {
+		st.deleteWhere = nil
+		return err
 	}
 
+	for column, value := range st.GetVersionWhere() {
+		where[column] = value
+	}
+	st.deleteWhere = where
+	return nil
+}
+
+func (st *SQLTool) prepare(action actionType, i interface{}, opts []sqlToolOpt) {
 	var (
 		iPkgPath   = reflect.TypeOf(i).PkgPath()
 		iName      = reflect.TypeOf(i).String()
 		needUpdate bool
 	)
+	if st.actionType != action {
+		st.actionType = action
+		needUpdate = true
+	}
 	if st.modelPkgPath != iPkgPath || st.modelName != iName {
 		st.modelPkgPath = iPkgPath
 		st.modelName = iName
 		needUpdate = true
 	}
 
+	// delete condition is only kept for model prepared by PrepareDelete, error is only kept for last prepared model
+	st.deleteWhere = nil
+	st.prepareErr = nil
+
 	// apply opts
 	for _, o := range opts {
 		needUpdate = o.Apply(st) || needUpdate
@@ -92,13 +114,17 @@ func (st *SQLTool) PrepareUpdate(i interface{}, opts ...sqlToolOpt) {
 	if needUpdate {
 		st.parseColumns(i)
 	}
-	st.values = st.PrepareValues(i)
 }
 
 func (st *SQLTool) parseColumns(i interface{}) {
 	st.columns = make([]string, 0)
+	st.allColumns = make([]string, 0)
 	st.column2FieldName = make(map[string]string, 0)
 	st.column2Type = make(map[string]reflect.Type, 0)
+	st.column2Tag = make(map[string]map[string]string, 0)
+	st.column2FieldIndex = make(map[string][]int, 0)
+	st.column2NestedGroups = make(map[string][]string, 0)
+	st.relations = make(map[string]relation, 0)
 
 	if len(st.allowColumns) > 0 && len(st.ignoreColumns) > 0 {
 		fmt.Println("[sqltool] allow columns opt has higher priority than ignore columns opt when scan struct")
@@ -107,25 +133,89 @@ func (st *SQLTool) parseColumns(i interface{}) {
 	t := reflect.TypeOf(i).Elem()
 	for index := 0; index < t.NumField(); index++ {
 		f := t.Field(index)
-		jsonTags := internal.TrimedSpaceStringSlice(f.Tag.Get("json"), ",")
-		if len(jsonTags) == 0 || jsonTags[0] == "-" {
+		dbName, dbFlags := parseDBTag(f.Tag.Get("db"))
+		if rel, ok := parseRelation(f, dbFlags); ok {
+			st.relations[f.Name] = rel
 			continue
 		}
-		st.addColumnFieldNameAndType(jsonTags[0], f.Name, f.Type)
+
+		if isNestedField(f, dbName, dbFlags) {
+			if st.actionType == selectAction {
+				st.parseNestedColumns(f, dbName, f.Index, nil)
+			}
+			continue
+		}
+
+		column, ok := st.columnName(f)
+		if !ok {
+			continue
+		}
+		st.column2Tag[column] = dbFlags
+		st.column2FieldIndex[column] = f.Index
+		st.addColumnFieldNameAndType(column, f.Name, f.Type)
+	}
+}
+
+// parseDBTag -- parse db tag in form `db:"name,flag,key=value"`, name is optional
+func parseDBTag(tag string) (string, map[string]string) {
+	flags := make(map[string]string)
+	if len(tag) == 0 {
+		return "", flags
 	}
+
+	parts := strings.Split(tag, ",")
+	for _, p := range parts[1:] {
+		p = strings.TrimSpace(p)
+		if len(p) == 0 {
+			continue
+		}
+
+		if i := strings.Index(p, "="); i >= 0 {
+			flags[strings.TrimSpace(p[:i])] = strings.TrimSpace(p[i+1:])
+		} else {
+			flags[p] = ""
+		}
+	}
+
+	return strings.TrimSpace(parts[0]), flags
 }
 
-// Use to add column, FieldName, Type to SQLTool
+// taggedColumn -- first column has flag in db tag
+func (st *SQLTool) taggedColumn(flag string) string {
+	for _, column := range st.allColumns {
+		if _, ok := st.column2Tag[column][flag]; ok {
+			return column
+		}
+	}
+
+	return ""
+}
+
+// getVersionColumn -- version column set by VersionColumnOpt or `db:",version"` tag
+func (st *SQLTool) getVersionColumn() string {
+	if len(st.versionColumn) > 0 {
+		return st.versionColumn
+	}
+
+	return st.taggedColumn("version")
+}
+
+// Use to add column, FieldName, Type to SQLTool, FieldName and Type of ignored column are kept for lookup
 func (st *SQLTool) addColumnFieldNameAndType(column string, name string, typeV reflect.Type) {
+	st.allColumns = append(st.allColumns, column)
+	st.column2FieldName[column] = name
+	st.column2Type[column] = typeV
 	if st.isIgnoreColumn(column) {
 		return
 	}
 	st.columns = append(st.columns, column)
-	st.column2FieldName[column] = name
-	st.column2Type[column] = typeV
 }
 
 func (st *SQLTool) isIgnoreColumn(column string) bool {
+	if st.actionType == deleteAction {
+		return !st.isDeleteColumn(column)
+	}
+
 	if _, ok := st.autoCreateDateTimeColumns[column]; ok {
 		if st.actionType == insertAction {
 			return false
@@ -146,6 +236,11 @@ func (st *SQLTool) isIgnoreColumn(column string) bool {
 		return true
 	}
 
+	// soft delete column is only written by GetSoftDeleteMap
+	if column == st.getSoftDeleteColumn() && (st.actionType == insertAction || st.actionType == updateAction) {
+		return true
+	}
+
 	if len(st.allowColumns) > 0 {
 		if _, ok := st.allowColumns[column]; ok {
 			return false
@@ -161,15 +256,36 @@ func (st *SQLTool) isIgnoreColumn(column string) bool {
 	return false
 }
 
+// isDeleteColumn -- column used to build DELETE command: primary key, version and soft delete column
+func (st *SQLTool) isDeleteColumn(column string) bool {
+	if internal.IsStringSliceContains(st.primaryKeyColumns(), column) {
+		return true
+	}
+
+	return column == st.getVersionColumn() || column == st.getSoftDeleteColumn()
+}
+
 // GetColumns -- Use to get list columns when do SELECT command
 func (st *SQLTool) GetColumns() []string {
 	return st.columns
 }
 
-// PrepareValues -- help parse struct values for batch INSERT command
+// PrepareValues -- help parse struct values for batch INSERT command. Error when value can not be prepared is returned
+// by next Exec, use PrepareValuesE to get error immediately
 func (st *SQLTool) PrepareValues(i interface{}) []interface{} {
+	values, err := st.PrepareValuesE(i)
+	if err != nil {
+		st.prepareErr = err
+	}
+
+	return values
+}
+
+// PrepareValuesE -- same as PrepareValues, error is returned when value of enum is not registered or value can not
+// be encoded by codec of column
+func (st *SQLTool) PrepareValuesE(i interface{}) ([]interface{}, error) {
 	if internal.IsZeroOfUnderlyingType(i) {
-		return nil
+		return nil, nil
 	}
 
 	values := make([]interface{}, 0)
@@ -204,28 +320,41 @@ func (st *SQLTool) PrepareValues(i interface{}) []interface{} {
 
 		vType, _ := st.column2Type[column]
 
-		if vType.Kind() == reflect.Slice && vType.Elem().Kind() == reflect.Uint8 {
-			values = append(values, convertedValue)
-			continue
-		}
-
 		var errMarshal error
-		switch vType.Kind() {
-		case reflect.Slice, reflect.Struct, reflect.Ptr, reflect.Map:
-			if internal.IsZeroOfUnderlyingType(fieldValueInterface) {
-				convertedValue = nil
+		if e, ok := lookupEnum(vType); ok {
+			if convertedValue != nil && !(vType.Kind() == reflect.Ptr && fieldValue.IsNil()) {
+				dbValue, err := enumValue(e, column, reflect.Indirect(fieldValue).Interface())
+				if err != nil {
+					return nil, err
+				}
+				convertedValue = dbValue
 			} else {
-				convertedValue, errMarshal = json.Marshal(convertedValue)
+				convertedValue = nil
+			}
+		} else if vType.Kind() != reflect.Slice || vType.Elem().Kind() != reflect.Uint8 {
+			// bytes are passed as is
+			switch vType.Kind() {
+			case reflect.Slice, reflect.Struct, reflect.Ptr, reflect.Map:
+				if internal.IsZeroOfUnderlyingType(fieldValueInterface) {
+					convertedValue = nil
+				} else {
+					convertedValue, errMarshal = st.encodeValue(column, convertedValue)
+				}
 			}
 		}
 		if errMarshal != nil {
-			panic(errMarshal)
+			return nil, fmt.Errorf("error while encode value of column %s: %v", column, errMarshal)
+		}
+
+		convertedValue, err := st.encryptColumnValue(column, convertedValue)
+		if err != nil {
+			return nil, err
 		}
 
 		values = append(values, convertedValue)
 	}
 
-	return values
+	return values, nil
 }
 
 // GetInsertValues -- Get values has been prepared by PrepareInsert
@@ -233,12 +362,25 @@ func (st *SQLTool) GetInsertValues() []interface{} {
 	return st.values
 }
 
-// GetUpdateMap -- Get map field - values has been prepared by PrepareUpdate
+// GetUpdateMap -- Get map field - values has been prepared by PrepareUpdate, only changed columns and
+// auto update date/time columns are returned when model is tracked. Primary key columns are excluded and version column
+// is increased, use with GetVersionWhere
 func (st *SQLTool) GetUpdateMap() map[string]interface{} {
 	m := make(map[string]interface{})
+	versionColumn := st.getVersionColumn()
+	primaryKeys := st.primaryKeyColumns()
 
 	for k, f := range st.columns {
-		if f == st.serialColumn {
+		if f == st.serialColumn || internal.IsStringSliceContains(primaryKeys, f) {
+			continue
+		}
+
+		if f == versionColumn {
+			m[f] = squirrel.Expr(f + " + 1")
+			continue
+		}
+
+		if st.dirtyColumns != nil && !st.dirtyColumns[f] && !st.autoUpdateDateTimeColumns[f] {
 			continue
 		}
 
@@ -247,3 +389,42 @@ func (st *SQLTool) GetUpdateMap() map[string]interface{} {
 
 	return m
 }
+
+// GetDeleteWhere -- Get condition has been prepared by PrepareDelete: primary key, version and soft delete column is null.
+// Condition match no row when PrepareDelete is not called or failed
+func (st *SQLTool) GetDeleteWhere() squirrel.Eq {
+	where := squirrel.Eq{}
+	if len(st.deleteWhere) == 0 {
+		// empty list is built as (1=0)
+		where[st.primaryKeyColumns()[0]] = []interface{}{}
+		return where
+	}
+
+	for column, value := range st.deleteWhere {
+		where[column] = value
+	}
+
+	if softDeleteColumn := st.getSoftDeleteColumn(); len(softDeleteColumn) > 0 {
+		where[softDeleteColumn] = nil
+	}
+
+	return where
+}
+
+// GetSoftDeleteMap -- Get map field - values for soft delete UPDATE command prepared by PrepareDelete, empty when
+// model has no soft delete column
+func (st *SQLTool) GetSoftDeleteMap() map[string]interface{} {
+	m := make(map[string]interface{})
+
+	softDeleteColumn := st.getSoftDeleteColumn()
+	if len(softDeleteColumn) == 0 {
+		return m
+	}
+
+	m[softDeleteColumn] = st.softDeleteValue(st.deleteTime)
+	if versionColumn := st.getVersionColumn(); len(versionColumn) > 0 {
+		m[versionColumn] = squirrel.Expr(versionColumn + " + 1")
+	}
+
+	return m
+}
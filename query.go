@@ -3,14 +3,17 @@ package sqltool
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/wizk3y/go-sqltool/internal"
 )
 
+// ErrTooManyRows -- returned by SelectOne when StrictOneRowOpt is set and query return more than one row
+var ErrTooManyRows = errors.New("sql: more than one row in result set")
+
 // SelectOne -- do select one row
 func (st *SQLTool) SelectOne(dest interface{}, query string, args ...interface{}) (err error) {
 	rows, err := st.queryContext(st.ctx, query, args...)
@@ -32,20 +35,67 @@ func (st *SQLTool) SelectOne(dest interface{}, query string, args ...interface{}
 	base := internal.Deref(v.Type())
 
 	if rows.Next() {
+		// row is filled before next row is read, scanned RawBytes are overwritten by rows.Next
 		vp = reflect.New(base)
 		err = st.scanAndFill(rows, vp.Interface())
+		if err == nil && st.strictOneRow && rows.Next() {
+			err = ErrTooManyRows
+		}
 		if err == nil {
 			direct.Set(vp.Elem())
+			if st.dirtyTracking {
+				st.track(vp)
+			}
+
+			// related rows are queried after result set released
+			rows.Close()
+			err = st.preload(direct)
 		}
-	} else {
+	} else if err = rows.Err(); err == nil {
 		err = sql.ErrNoRows
 	}
 
 	return
 }
 
-// Select -- do select, same as SelectOne but return list results
+// RowError -- error of a row failed to scan, Index is zero-based position of row in result set
+type RowError struct {
+	Index int
+	Err   error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Index, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// RowErrors -- errors of rows skipped by ScanModeCollect
+type RowErrors []*RowError
+
+func (e RowErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, rowErr := range e {
+		msgs = append(msgs, rowErr.Error())
+	}
+
+	return fmt.Sprintf("%d rows failed to scan: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// Select -- do select, same as SelectOne but return list results, row failed to scan is logged and skipped unless ScanModeOpt is set.
+// sql.ErrNoRows is returned when no row matched unless AllowEmptyResultOpt is set
 func (st *SQLTool) Select(dest interface{}, query string, args ...interface{}) error {
+	return st.selectList(ScanModeSkip, st.allowEmptyResult, dest, query, args...)
+}
+
+// SelectAll -- same as Select but stop at first row failed to scan unless ScanModeOpt is set
+func (st *SQLTool) SelectAll(dest interface{}, query string, args ...interface{}) error {
+	return st.selectList(ScanModeAbort, st.allowEmptyResult, dest, query, args...)
+}
+
+func (st *SQLTool) selectList(defaultMode ScanMode, allowEmpty bool, dest interface{}, query string, args ...interface{}) error {
 	rows, err := st.queryContext(st.ctx, query, args...)
 	if err != nil {
 		return err
@@ -70,19 +120,35 @@ func (st *SQLTool) Select(dest interface{}, query string, args ...interface{}) e
 		return err
 	}
 
+	mode := st.scanMode
+	if mode == 0 {
+		mode = defaultMode
+	}
+
 	isPtr := slice.Elem().Kind() == reflect.Ptr
 	base := internal.Deref(slice.Elem())
 	empty := true
+	var rowErrs RowErrors
 
-	for rows.Next() {
+	for index := 0; rows.Next(); index++ {
 		vp = reflect.New(base)
 		err = st.scanAndFill(rows, vp.Interface())
 		if err != nil {
-			fmt.Printf("[sqltool] error while scan and fill values, details: %v", err)
+			switch mode {
+			case ScanModeAbort:
+				return &RowError{Index: index, Err: err}
+			case ScanModeCollect:
+				rowErrs = append(rowErrs, &RowError{Index: index, Err: err})
+			default:
+				fmt.Printf("[sqltool] error while scan and fill values, row: %d, details: %v", index, err)
+			}
 			continue
 		}
 
 		empty = false
+		if st.dirtyTracking {
+			st.track(vp)
+		}
 
 		// append
 		if isPtr {
@@ -92,24 +158,242 @@ func (st *SQLTool) Select(dest interface{}, query string, args ...interface{}) e
 		}
 	}
 
+	err = rows.Err()
+	if err != nil {
+		return err
+	}
+
+	err = st.preload(direct)
+	if err != nil {
+		return err
+	}
+
+	if len(rowErrs) > 0 {
+		return rowErrs
+	}
+
 	if empty {
-		err = sql.ErrNoRows
+		if !allowEmpty {
+			return sql.ErrNoRows
+		}
+
+		if direct.IsNil() {
+			direct.Set(reflect.MakeSlice(slice, 0, 0))
+		}
 	}
 
-	return err
+	return nil
 }
 
-func (st *SQLTool) queryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
-	var (
-		stmt *sql.Stmt
-		err  error
-	)
+// SelectMaps -- do select and scan rows to maps column - value, use for query which result shape is unknown at compile time.
+// Value is converted by database type of column: text as string, integer as int64, numeric as float64,
+// date/time as timestamp by DateTimeUnitOpt and binary as []byte
+func (st *SQLTool) SelectMaps(query string, args ...interface{}) ([]map[string]interface{}, error) {
+	rows, err := st.queryContext(st.ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]map[string]interface{}, 0)
+	for index := 0; rows.Next(); index++ {
+		m, err := st.scanMapRow(rows, columnTypes)
+		if err != nil {
+			return nil, &RowError{Index: index, Err: err}
+		}
 
-	if st.isTransaction {
-		stmt, err = st.tx.PrepareContext(ctx, query)
-	} else {
-		stmt, err = st.db.PrepareContext(ctx, query)
+		results = append(results, m)
 	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// SelectMapOne -- same as SelectMaps but return first row, sql.ErrNoRows is returned when no row matched
+func (st *SQLTool) SelectMapOne(query string, args ...interface{}) (map[string]interface{}, error) {
+	rows, err := st.queryContext(st.ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return nil, err
+		}
+
+		return nil, sql.ErrNoRows
+	}
+
+	m, err := st.scanMapRow(rows, columnTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	if st.strictOneRow && rows.Next() {
+		return nil, ErrTooManyRows
+	}
+
+	return m, nil
+}
+
+// SelectScalar -- do select single column and fill value of first row to dest, e.g. COUNT(*), MAX(id).
+// dest can be pointer to any type supported by struct field, use pointer of pointer for nullable value
+func (st *SQLTool) SelectScalar(dest interface{}, query string, args ...interface{}) error {
+	target, err := scalarTarget(dest)
+	if err != nil {
+		return err
+	}
+	if err = checkScalarType(target.Type()); err != nil {
+		return err
+	}
+
+	rows, err := st.queryContext(st.ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	column, isDateTime, err := st.scalarColumn(rows)
+	if err != nil {
+		return err
+	}
+
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return err
+		}
+
+		return sql.ErrNoRows
+	}
+
+	value := newScanValue(target.Type(), isDateTime)
+	err = rows.Scan(value)
+	if err != nil {
+		return err
+	}
+
+	// value is filled before next row is read, scanned RawBytes are overwritten by rows.Next
+	filled := reflect.New(target.Type()).Elem()
+	st.fillValueBySQLType(filled, column, target.Type(), value, isDateTime)
+	if st.strictOneRow && rows.Next() {
+		return ErrTooManyRows
+	}
+
+	target.Set(filled)
+	return nil
+}
+
+// SelectColumn -- do select single column and append value of each row to dest, dest must be pointer to slice.
+// Empty slice and nil error is returned when no row matched
+func (st *SQLTool) SelectColumn(dest interface{}, query string, args ...interface{}) error {
+	target, err := scalarTarget(dest)
+	if err != nil {
+		return err
+	}
+	if target.Kind() != reflect.Slice {
+		return fmt.Errorf("expected %s but got %s", reflect.Slice, target.Kind())
+	}
+	if err = checkScalarType(target.Type().Elem()); err != nil {
+		return err
+	}
+
+	rows, err := st.queryContext(st.ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	column, isDateTime, err := st.scalarColumn(rows)
+	if err != nil {
+		return err
+	}
+
+	base := target.Type().Elem()
+	if target.IsNil() {
+		target.Set(reflect.MakeSlice(target.Type(), 0, 0))
+	}
+
+	for index := 0; rows.Next(); index++ {
+		value := newScanValue(base, isDateTime)
+		err = rows.Scan(value)
+		if err != nil {
+			return &RowError{Index: index, Err: err}
+		}
+
+		elem := reflect.New(base).Elem()
+		st.fillValueBySQLType(elem, column, base, value, isDateTime)
+		target.Set(reflect.Append(target, elem))
+	}
+
+	return rows.Err()
+}
+
+func scalarTarget(dest interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr {
+		return reflect.Value{}, errors.New("must pass a pointer, not a value, to scan destination")
+	}
+	if v.IsNil() {
+		return reflect.Value{}, errors.New("nil pointer passed to scan destination")
+	}
+
+	return v.Elem(), nil
+}
+
+// checkScalarType -- error when value of type can not be scanned by newScanValue and filled by fillValueBySQLType
+func checkScalarType(vType reflect.Type) error {
+	t := vType
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Slice, reflect.Map:
+		return nil
+	case reflect.Struct:
+		// time is not encoded by codec
+		if t != timeType {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unsupported destination type %s", vType)
+}
+
+// scalarColumn -- get name of the only column of rows, and whether it hold date/time value
+func (st *SQLTool) scalarColumn(rows *sql.Rows) (string, bool, error) {
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return "", false, err
+	}
+	if len(columnTypes) != 1 {
+		return "", false, fmt.Errorf("expected 1 column but got %d", len(columnTypes))
+	}
+
+	column := columnTypes[0].Name()
+	isDateTime := st.isDateTimeColumn(column) || columnKindOf(columnTypes[0].DatabaseTypeName()) == columnKindDateTime
+
+	return column, isDateTime, nil
+}
+
+func (st *SQLTool) queryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	stmt, err := st.executor().PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -120,38 +404,24 @@ func (st *SQLTool) queryContext(ctx context.Context, query string, args ...inter
 
 // scanAndFill -- scan row then fill to dest
 func (st *SQLTool) scanAndFill(rows *sql.Rows, dest interface{}) (err error) {
+	columns := st.scanColumns(rows)
+
 	values := make([]interface{}, 0)
-	for _, column := range st.columns {
+	for _, column := range columns {
 		vType, _ := st.column2Type[column]
-		switch vType.Kind() {
-		case reflect.String:
-			var nstr = &sql.NullString{}
-			values = append(values, nstr)
-		case reflect.Bool:
-			var nbool = &sql.NullBool{}
-			values = append(values, nbool)
-		case reflect.Float32, reflect.Float64:
-			var nfloat64 = &sql.NullFloat64{}
-			values = append(values, nfloat64)
-		case reflect.Int64:
-			if internal.IsStringSliceContains(st.dateTimeColumns, column) {
-				var ntime = &sql.NullTime{}
-				values = append(values, ntime)
-			} else {
-				var nint64 = &sql.NullInt64{}
-				values = append(values, nint64)
-			}
-		case reflect.Int32, reflect.Int16, reflect.Int8, reflect.Int:
-			var nint64 = &sql.NullInt64{}
-			values = append(values, nint64)
-		case reflect.Slice, reflect.Struct, reflect.Map, reflect.Ptr:
-			var nbytes = &sql.RawBytes{}
-			values = append(values, nbytes)
+		if encrypted, _ := st.encryptionMode(column); encrypted {
+			values = append(values, &sql.NullString{})
+			continue
 		}
+		if _, ok := lookupEnum(vType); ok {
+			values = append(values, &sql.NullString{})
+			continue
+		}
+		values = append(values, newScanValue(vType, st.isDateTimeColumn(column)))
 	}
 	err = rows.Scan(values...)
 	if err != nil {
-		fmt.Printf("[sqltool] error while scan sql.Rows, fields: %v, details: %v", st.columns, err)
+		fmt.Printf("[sqltool] error while scan sql.Rows, fields: %v, details: %v", columns, err)
 		return
 	}
 
@@ -163,50 +433,124 @@ func (st *SQLTool) scanAndFill(rows *sql.Rows, dest interface{}) (err error) {
 		return errors.New("nil pointer passed to StructScan destination")
 	}
 
+	// nested pointer stay nil when all of its columns are NULL
+	nullGroups := st.nullNestedGroups(columns, values)
+
 	ve := v.Elem()
-	for index, column := range st.columns {
-		// get field name
-		fieldName, _ := st.column2FieldName[column]
+	for index, column := range columns {
+		if st.inNullGroup(column, nullGroups) {
+			continue
+		}
+
+		// get field by index, nested pointer is allocated
+		field := internal.FieldByIndexAlloc(ve, st.column2FieldIndex[column])
 		vType, _ := st.column2Type[column]
 		value := values[index]
 
-		st.fillValueBySQLType(ve, column, fieldName, vType, value, false)
+		if encrypted, _ := st.encryptionMode(column); encrypted {
+			err = st.fillEncrypted(field, column, vType, value.(*sql.NullString))
+			if err != nil {
+				return
+			}
+			continue
+		}
+
+		if e, ok := lookupEnum(vType); ok {
+			err = st.fillEnum(field, column, vType, e, value.(*sql.NullString))
+			if err != nil {
+				return
+			}
+			continue
+		}
+
+		st.fillValueBySQLType(field, column, vType, value, st.isDateTimeColumn(column))
 	}
 
 	return
 }
 
-func (st *SQLTool) fillValueBySQLType(ve reflect.Value, column, fieldName string, vType reflect.Type, value interface{}, ptr bool) {
+// scanColumns -- columns of model in order of result set when every result column is found by name, `user.id` is
+// matched with nested column `user__id`. Otherwise columns of model are used in prepared order
+func (st *SQLTool) scanColumns(rows *sql.Rows) []string {
+	names, err := rows.Columns()
+	if err != nil {
+		return st.columns
+	}
+
+	columns := make([]string, 0, len(names))
+	for _, name := range names {
+		column := strings.ReplaceAll(name, ".", nestedColumnSeparator)
+		if _, ok := st.column2FieldIndex[column]; !ok {
+			return st.columns
+		}
+
+		columns = append(columns, column)
+	}
+
+	return columns
+}
+
+func (st *SQLTool) isDateTimeColumn(column string) bool {
+	return internal.IsStringSliceContains(st.dateTimeColumns, column)
+}
+
+// newScanValue -- get nullable holder to scan value of type
+func newScanValue(vType reflect.Type, isDateTime bool) interface{} {
 	switch vType.Kind() {
 	case reflect.String:
-		v := value.(*sql.NullString).String
+		var nstr = &sql.NullString{}
+		return nstr
+	case reflect.Bool:
+		var nbool = &sql.NullBool{}
+		return nbool
+	case reflect.Float32, reflect.Float64:
+		var nfloat64 = &sql.NullFloat64{}
+		return nfloat64
+	case reflect.Int64:
+		if isDateTime {
+			var ntime = &nullTime{}
+			return ntime
+		}
 
-		if ptr {
-			ve.FieldByName(fieldName).Set(reflect.ValueOf(internal.StringPtr(v)))
-		} else {
-			ve.FieldByName(fieldName).SetString(v)
+		var nint64 = &sql.NullInt64{}
+		return nint64
+	case reflect.Int32, reflect.Int16, reflect.Int8, reflect.Int:
+		var nint64 = &sql.NullInt64{}
+		return nint64
+	case reflect.Ptr:
+		if isDateTime && vType.Elem().Kind() == reflect.Int64 {
+			var ntime = &nullTime{}
+			return ntime
 		}
+
+		fallthrough
+	case reflect.Slice, reflect.Struct, reflect.Map:
+		var nbytes = &sql.RawBytes{}
+		return nbytes
+	}
+
+	return nil
+}
+
+func (st *SQLTool) fillValueBySQLType(field reflect.Value, column string, vType reflect.Type, value interface{}, isDateTime bool) {
+	switch vType.Kind() {
+	case reflect.String:
+		v := value.(*sql.NullString).String
+
+		field.SetString(v)
 	case reflect.Bool:
 		v := value.(*sql.NullBool).Bool
 
-		if ptr {
-			ve.FieldByName(fieldName).Set(reflect.ValueOf(internal.BoolPtr(v)))
-		} else {
-			ve.FieldByName(fieldName).SetBool(v)
-		}
+		field.SetBool(v)
 	case reflect.Float32, reflect.Float64:
 		v := value.(*sql.NullFloat64).Float64
 
-		if ptr {
-			ve.FieldByName(fieldName).Set(reflect.ValueOf(internal.Float64Ptr(v)))
-		} else {
-			ve.FieldByName(fieldName).SetFloat(v)
-		}
+		field.SetFloat(v)
 	case reflect.Int64:
-		if internal.IsStringSliceContains(st.dateTimeColumns, column) {
-			var vtime = value.(*sql.NullTime)
+		if isDateTime {
+			var vtime = value.(*nullTime)
 			if vtime.Valid {
-				ve.FieldByName(fieldName).SetInt(internal.TimestampByUnit(vtime.Time, st.dateTimeUnit))
+				field.SetInt(internal.TimestampByUnit(vtime.Time, st.dateTimeUnit))
 			}
 			break
 		}
@@ -214,26 +558,31 @@ func (st *SQLTool) fillValueBySQLType(ve reflect.Value, column, fieldName string
 	case reflect.Int32, reflect.Int16, reflect.Int8, reflect.Int:
 		v := value.(*sql.NullInt64).Int64
 
-		if ptr {
-			ve.FieldByName(fieldName).Set(reflect.ValueOf(internal.Int64Ptr(v)))
-		} else {
-			ve.FieldByName(fieldName).SetInt(v)
-		}
+		field.SetInt(v)
 	case reflect.Ptr:
+		if vtime, ok := value.(*nullTime); ok {
+			if vtime.Valid {
+				ts := reflect.New(vType.Elem())
+				ts.Elem().SetInt(internal.TimestampByUnit(vtime.Time, st.dateTimeUnit))
+				field.Set(ts)
+			}
+			break
+		}
+
 		val := value.(*sql.RawBytes)
 		if len(*val) < 1 {
 			break
 		}
 
-		fillValueByType(ve, fieldName, internal.Deref(vType), string(*val), true)
+		fillValueByType(field, column, internal.Deref(vType), string(*val), true, st.codecOf(column))
 	case reflect.Slice:
 		val := value.(*sql.RawBytes)
 
 		// If the slice is slice of bytes
-		if ve.FieldByName(fieldName).Type().Elem().Kind() == reflect.Uint8 {
+		if vType.Elem().Kind() == reflect.Uint8 {
 			var tmp = make([]byte, len(*val))
 			copy(tmp, *val)
-			ve.FieldByName(fieldName).SetBytes(tmp)
+			field.SetBytes(tmp)
 			break
 		}
 
@@ -244,29 +593,35 @@ func (st *SQLTool) fillValueBySQLType(ve reflect.Value, column, fieldName string
 			break
 		}
 
-		fillValueByType(ve, fieldName, vType, string(*val), false)
+		fillValueByType(field, column, vType, string(*val), false, st.codecOf(column))
 	}
 }
 
-func fillValueByType(ve reflect.Value, fieldName string, vType reflect.Type, valueStr string, ptr bool) {
+func fillValueByType(field reflect.Value, column string, vType reflect.Type, valueStr string, ptr bool, codec Codec) {
 	switch vType.Kind() {
 	case reflect.String, reflect.Bool, reflect.Float64, reflect.Float32, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		value := internal.CastValueTo(valueStr, vType, ptr)
+		value := reflect.ValueOf(internal.CastValueTo(valueStr, vType, false)).Convert(vType)
 
-		ve.FieldByName(fieldName).Set(reflect.ValueOf(value))
+		if ptr {
+			dataValue := reflect.New(vType)
+			dataValue.Elem().Set(value)
+			field.Set(dataValue)
+		} else {
+			field.Set(value)
+		}
 	case reflect.Slice, reflect.Struct, reflect.Map:
 		var dataValue reflect.Value
 		dataValue = reflect.New(vType)
-		err := json.Unmarshal([]byte(valueStr), dataValue.Interface())
+		err := codec.Decode([]byte(valueStr), dataValue.Interface())
 		if err != nil {
-			fmt.Printf("[sqltool] error while parse value to slice/struct/map, field: %s, details: %v", fieldName, err)
+			fmt.Printf("[sqltool] error while parse value to slice/struct/map, column: %s, details: %v", column, err)
 			break
 		}
 
 		if ptr {
-			ve.FieldByName(fieldName).Set(dataValue)
+			field.Set(dataValue)
 		} else {
-			ve.FieldByName(fieldName).Set(dataValue.Elem())
+			field.Set(dataValue.Elem())
 		}
 	}
 }
@@ -0,0 +1,217 @@
+package sqltool_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/wizk3y/go-sqltool"
+)
+
+type queryTestUser struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+func Test_SQLTool_SelectScanMode(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	query := "SELECT id, username FROM user"
+	newRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "username"}).
+			AddRow(1, "first").
+			AddRow("broken", "second").
+			AddRow(3, "third")
+	}
+
+	mock.ExpectPrepare(query).ExpectQuery().WillReturnRows(newRows())
+	mock.ExpectPrepare(query).ExpectQuery().WillReturnRows(newRows())
+	mock.ExpectPrepare(query).ExpectQuery().WillReturnRows(newRows().RowError(2, errors.New("connection reset")))
+
+	// real code
+	sqlTool := sqltool.NewTool(context.Background(), db)
+	sqlTool.PrepareSelect(&queryTestUser{})
+
+	var res []queryTestUser
+	err = sqlTool.SelectAll(&res, query)
+	var rowErr *sqltool.RowError
+	if !errors.As(err, &rowErr) || rowErr.Index != 1 {
+		t.Fatalf("expected error of row 1, details: %v", err)
+	}
+
+	res = nil
+	sqlTool.PrepareSelect(&queryTestUser{}, sqltool.ScanModeOpt(sqltool.ScanModeCollect))
+	err = sqlTool.SelectAll(&res, query)
+	rowErrs, ok := err.(sqltool.RowErrors)
+	if !ok || len(rowErrs) != 1 || rowErrs[0].Index != 1 {
+		t.Fatalf("expected collected error of row 1, details: %v", err)
+	}
+	if len(res) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(res))
+	}
+
+	res = nil
+	sqlTool.PrepareSelect(&queryTestUser{}, sqltool.ScanModeOpt(sqltool.ScanModeSkip))
+	err = sqlTool.Select(&res, query)
+	if err == nil || err.Error() != "connection reset" {
+		t.Fatalf("expected error of rows, details: %v", err)
+	}
+}
+
+func Test_SQLTool_SelectEmptyResult(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	query := "SELECT id, username FROM user WHERE username = ?"
+
+	mock.ExpectPrepare(query).ExpectQuery().WithArgs("nobody").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}))
+	mock.ExpectPrepare(query).ExpectQuery().WithArgs("twins").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(1, "twins").AddRow(2, "twins"))
+	mock.ExpectPrepare("SELECT username FROM user").ExpectQuery().
+		WillReturnRows(sqlmock.NewRows([]string{"username"}).AddRow("first").AddRow("second"))
+
+	// real code
+	sqlTool := sqltool.NewTool(context.Background(), db)
+	sqlTool.PrepareSelect(&queryTestUser{},
+		sqltool.AllowEmptyResultOpt(true),
+		sqltool.StrictOneRowOpt(true),
+	)
+
+	var res []queryTestUser
+	err = sqlTool.Select(&res, query, "nobody")
+	if err != nil {
+		t.Fatalf("error when execute select, details: %v", err)
+	}
+	if res == nil || len(res) != 0 {
+		t.Fatalf("expected empty non-nil slice, got %#v", res)
+	}
+
+	var one queryTestUser
+	err = sqlTool.SelectOne(&one, query, "twins")
+	if err != sqltool.ErrTooManyRows {
+		t.Fatalf("expected ErrTooManyRows, details: %v", err)
+	}
+
+	username := "unchanged"
+	err = sqlTool.SelectScalar(&username, "SELECT username FROM user")
+	if err != sqltool.ErrTooManyRows {
+		t.Fatalf("expected ErrTooManyRows, details: %v", err)
+	}
+	if username != "unchanged" {
+		t.Fatalf("expected dest unchanged, got %s", username)
+	}
+}
+
+func Test_SQLTool_SelectMaps(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	query := "SELECT id, username, balance, created_at, open_at, tags FROM user"
+
+	mock.ExpectPrepare(query).
+		ExpectQuery().
+		WillReturnRows(
+			sqlmock.NewRowsWithColumnDefinition(
+				sqlmock.NewColumn("id").OfType("BIGINT", int64(0)),
+				sqlmock.NewColumn("username").OfType("VARCHAR", ""),
+				sqlmock.NewColumn("balance").OfType("DECIMAL", ""),
+				sqlmock.NewColumn("created_at").OfType("DATETIME", "").Nullable(true),
+				sqlmock.NewColumn("open_at").OfType("TIME", ""),
+				sqlmock.NewColumn("tags").OfType("_INT4", ""),
+			).
+				AddRow([]byte("1"), []byte("sample"), []byte("10.5"), []byte("2023-03-30 23:57:48"), []byte("08:30:00"), []byte("{1,2}")).
+				AddRow([]byte("2"), []byte("other"), []byte("0"), nil, []byte("09:00:00"), []byte("{}")),
+		)
+
+	// real code
+	sqlTool := sqltool.NewTool(context.Background(), db)
+	sqlTool.PrepareSelect(&queryTestUser{}, sqltool.DateTimeUnitOpt("s"))
+
+	res, err := sqlTool.SelectMaps(query)
+	if err != nil {
+		t.Fatalf("error when execute select maps, details: %v", err)
+	}
+	if len(res) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(res))
+	}
+
+	first := res[0]
+	if first["id"] != int64(1) || first["username"] != "sample" || first["balance"] != 10.5 || first["created_at"] != int64(1680220668) ||
+		first["open_at"] != "08:30:00" || first["tags"] != "{1,2}" {
+		t.Fatalf("unexpected converted values %#v", first)
+	}
+	if res[1]["created_at"] != nil {
+		t.Fatalf("expected nil for NULL column, got %#v", res[1]["created_at"])
+	}
+}
+
+func Test_SQLTool_SelectScalarAndColumn(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectPrepare("SELECT COUNT(*) FROM user").
+		ExpectQuery().
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(3))
+	mock.ExpectPrepare("SELECT MAX(created_at) AS created_at FROM user").
+		ExpectQuery().
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow("2023-03-30 23:57:48"))
+	mock.ExpectPrepare("SELECT nickname FROM user").
+		ExpectQuery().
+		WillReturnRows(sqlmock.NewRows([]string{"nickname"}).AddRow("abc").AddRow(nil))
+
+	// real code
+	sqlTool := sqltool.NewTool(context.Background(), db)
+	sqlTool.PrepareSelect(&queryTestUser{},
+		sqltool.DateTimeColumnsOpt([]string{"created_at"}),
+		sqltool.DateTimeUnitOpt("s"),
+	)
+
+	var count int
+	err = sqlTool.SelectScalar(&count, "SELECT COUNT(*) FROM user")
+	if err != nil || count != 3 {
+		t.Fatalf("expected count 3, got %d, details: %v", count, err)
+	}
+
+	var lastCreatedAt *int64
+	err = sqlTool.SelectScalar(&lastCreatedAt, "SELECT MAX(created_at) AS created_at FROM user")
+	if err != nil || lastCreatedAt == nil || *lastCreatedAt != 1680220668 {
+		t.Fatalf("expected converted timestamp, got %v, details: %v", lastCreatedAt, err)
+	}
+
+	// unsupported destination is rejected before query
+	var unsigned uint64
+	err = sqlTool.SelectScalar(&unsigned, "SELECT COUNT(*) FROM user")
+	if err == nil || err.Error() != "unsupported destination type uint64" {
+		t.Fatalf("expected unsupported destination type error, details: %v", err)
+	}
+	var times []time.Time
+	err = sqlTool.SelectColumn(&times, "SELECT created_at FROM user")
+	if err == nil || err.Error() != "unsupported destination type time.Time" {
+		t.Fatalf("expected unsupported destination type error, details: %v", err)
+	}
+
+	var nicknames []*string
+	err = sqlTool.SelectColumn(&nicknames, "SELECT nickname FROM user")
+	if err != nil {
+		t.Fatalf("error when execute select column, details: %v", err)
+	}
+	if len(nicknames) != 2 || *nicknames[0] != "abc" || nicknames[1] != nil {
+		t.Fatalf("unexpected column values %v", nicknames)
+	}
+}
@@ -0,0 +1,240 @@
+package sqltool
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/wizk3y/go-sqltool/internal"
+)
+
+// preloadChunkSize -- max number of key values in one IN condition when preload relation
+const preloadChunkSize = 500
+
+type relationKind string
+
+const (
+	relationHasOne    relationKind = "has_one"
+	relationHasMany   relationKind = "has_many"
+	relationBelongsTo relationKind = "belongs_to"
+)
+
+// TableNamer -- model know its table name, used to preload relation without table in tag
+type TableNamer interface {
+	TableName() string
+}
+
+// relation -- declared by tag on field of related model, e.g.
+// `db:",has_many,fk=order_id"`, `db:",has_one,fk=user_id,table=profile"`, `db:",belongs_to,fk=user_id,ref=id"`.
+// For has_one/has_many fk is column of related model and ref is column of model, default is primary key.
+// For belongs_to fk is column of model and ref is column of related model, default is its serial column
+type relation struct {
+	kind      relationKind
+	fieldName string
+	modelType reflect.Type
+	table     string
+	fk        string
+	ref       string
+}
+
+func parseRelation(f reflect.StructField, flags map[string]string) (relation, bool) {
+	rel := relation{
+		fieldName: f.Name,
+		table:     flags["table"],
+		fk:        flags["fk"],
+		ref:       flags["ref"],
+	}
+
+	for _, kind := range []relationKind{relationHasOne, relationHasMany, relationBelongsTo} {
+		if _, ok := flags[string(kind)]; ok {
+			rel.kind = kind
+		}
+	}
+	if len(rel.kind) == 0 {
+		return rel, false
+	}
+
+	rel.modelType = internal.Deref(f.Type)
+	if rel.kind == relationHasMany {
+		rel.modelType = internal.Deref(f.Type.Elem())
+	}
+
+	if len(rel.table) == 0 {
+		if namer, ok := reflect.New(rel.modelType).Interface().(TableNamer); ok {
+			rel.table = namer.TableName()
+		}
+	}
+
+	return rel, true
+}
+
+// preload -- load relations set by Preload to parents, parents is struct or slice of struct/pointer to struct
+func (st *SQLTool) preload(parents reflect.Value) error {
+	names := st.preloads
+	st.preloads = nil
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	items := make([]reflect.Value, 0)
+	if parents.Kind() == reflect.Slice {
+		for index := 0; index < parents.Len(); index++ {
+			items = append(items, reflect.Indirect(parents.Index(index)))
+		}
+	} else {
+		items = append(items, parents)
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	for _, name := range names {
+		rel, ok := st.relations[name]
+		if !ok {
+			return fmt.Errorf("relation %s is not declared in model %s", name, st.modelName)
+		}
+
+		err := st.preloadRelation(rel, items)
+		if err != nil {
+			return fmt.Errorf("preload %s: %v", name, err)
+		}
+	}
+
+	return nil
+}
+
+func (st *SQLTool) preloadRelation(rel relation, items []reflect.Value) error {
+	if len(rel.table) == 0 {
+		return fmt.Errorf("table of %s is unknown, set table in tag or implement TableNamer", rel.modelType)
+	}
+
+	// related rows are selected by tool sharing executor, transaction, dialect and model options
+	related := NewToolFromExecutor(st.ctx, st.exec)
+	related.txn = st.txn
+	related.dialect = st.dialect
+	related.dateTimeUnit = st.dateTimeUnit
+	related.namingStrategy = st.namingStrategy
+	related.columnCodecs = st.columnCodecs
+	related.encryptor = st.encryptor
+	related.encryptedColumns = st.encryptedColumns
+	related.enumPolicy = st.enumPolicy
+	related.PrepareSelect(reflect.New(rel.modelType).Interface())
+
+	// ownColumn is column of model, matchColumn is column of related model
+	ownColumn, matchColumn := rel.ref, rel.fk
+	if rel.kind == relationBelongsTo {
+		ownColumn, matchColumn = rel.fk, rel.ref
+		if len(matchColumn) == 0 {
+			matchColumn = related.serialColumn
+		}
+	} else if len(ownColumn) == 0 {
+		ownColumn = st.primaryKeyColumns()[0]
+	}
+
+	ownFieldName, ok := st.column2FieldName[ownColumn]
+	if !ok {
+		return fmt.Errorf("column %s is not found in model %s", ownColumn, st.modelName)
+	}
+	matchFieldName, ok := related.column2FieldName[matchColumn]
+	if !ok {
+		return fmt.Errorf("column %s is not found in model %s", matchColumn, related.modelName)
+	}
+
+	keys := make([]interface{}, 0)
+	seen := make(map[string]bool)
+	for _, item := range items {
+		key, keyStr, ok := relationKey(item.FieldByName(ownFieldName))
+		if !ok || internal.IsZeroOfUnderlyingType(key) || seen[keyStr] {
+			continue
+		}
+
+		seen[keyStr] = true
+		keys = append(keys, key)
+	}
+
+	results := reflect.New(reflect.SliceOf(reflect.PtrTo(rel.modelType)))
+	for start := 0; start < len(keys); start += preloadChunkSize {
+		end := start + preloadChunkSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		query, args, err := squirrel.Select(related.GetColumns()...).
+			From(rel.table).
+			Where(squirrel.Eq{matchColumn: keys[start:end]}).
+			Where(related.softDeleteWhere()).
+			PlaceholderFormat(related.dialect.placeholder()).
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		err = related.selectList(ScanModeAbort, true, results.Interface(), query, args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	grouped := make(map[string][]reflect.Value)
+	for index := 0; index < results.Elem().Len(); index++ {
+		child := results.Elem().Index(index)
+		_, keyStr, ok := relationKey(child.Elem().FieldByName(matchFieldName))
+		if !ok {
+			continue
+		}
+
+		grouped[keyStr] = append(grouped[keyStr], child)
+	}
+
+	for _, item := range items {
+		var children []reflect.Value
+		if _, keyStr, ok := relationKey(item.FieldByName(ownFieldName)); ok {
+			children = grouped[keyStr]
+		}
+		setRelationField(item.FieldByName(rel.fieldName), children)
+	}
+
+	return nil
+}
+
+// relationKey -- value of key field with pointer dereferenced and its string to match parent and related rows,
+// false when key is nil pointer
+func relationKey(field reflect.Value) (interface{}, string, bool) {
+	for field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil, "", false
+		}
+		field = field.Elem()
+	}
+
+	key := field.Interface()
+	return key, fmt.Sprint(key), true
+}
+
+// setRelationField -- set related models to field, children are pointers to related model
+func setRelationField(field reflect.Value, children []reflect.Value) {
+	fieldType := field.Type()
+
+	switch fieldType.Kind() {
+	case reflect.Slice:
+		isPtr := fieldType.Elem().Kind() == reflect.Ptr
+		slice := reflect.MakeSlice(fieldType, 0, len(children))
+		for _, child := range children {
+			if isPtr {
+				slice = reflect.Append(slice, child)
+			} else {
+				slice = reflect.Append(slice, child.Elem())
+			}
+		}
+		field.Set(slice)
+	case reflect.Ptr:
+		if len(children) > 0 {
+			field.Set(children[0])
+		}
+	case reflect.Struct:
+		if len(children) > 0 {
+			field.Set(children[0].Elem())
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package sqltool_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/wizk3y/go-sqltool"
+)
+
+type relationTestOrder struct {
+	ID       int64                   `json:"id"`
+	UserID   int64                   `json:"user_id"`
+	Items    []*relationTestLineItem `json:"items" db:",has_many,fk=order_id,table=line_item"`
+	Customer *relationTestCustomer   `json:"customer" db:",belongs_to,fk=user_id"`
+}
+
+type relationTestLineItem struct {
+	ID      int64  `json:"id"`
+	OrderID int64  `json:"order_id"`
+	Sku     string `json:"sku"`
+}
+
+type relationTestCustomer struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func (relationTestCustomer) TableName() string {
+	return "customer"
+}
+
+func Test_SQLTool_Preload(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectPrepare("SELECT id, user_id FROM orders").
+		ExpectQuery().
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(1, 10).AddRow(2, 10).AddRow(3, 11))
+	mock.ExpectPrepare("SELECT id, order_id, sku FROM line_item WHERE order_id IN (?,?,?)").
+		ExpectQuery().
+		WithArgs(1, 2, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "sku"}).AddRow(1, 1, "a").AddRow(2, 1, "b").AddRow(3, 3, "c"))
+	mock.ExpectPrepare("SELECT id, name FROM customer WHERE id IN (?,?)").
+		ExpectQuery().
+		WithArgs(10, 11).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(10, "alice").AddRow(11, "bob"))
+
+	// real code
+	sqlTool := sqltool.NewTool(context.Background(), db)
+	sqlTool.PrepareSelect(&relationTestOrder{}, sqltool.Preload("Items", "Customer"))
+
+	var orders []relationTestOrder
+	err = sqlTool.SelectAll(&orders, "SELECT id, user_id FROM orders")
+	if err != nil {
+		t.Fatalf("error when select with preload, details: %v", err)
+	}
+
+	if len(orders[0].Items) != 2 || len(orders[1].Items) != 0 || orders[1].Items == nil || orders[2].Items[0].Sku != "c" {
+		t.Fatalf("unexpected preloaded items %+v", orders)
+	}
+	if orders[1].Customer.Name != "alice" || orders[2].Customer.Name != "bob" {
+		t.Fatalf("unexpected preloaded customers %+v", orders)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unfulfilled expectations, details: %v", err)
+	}
+}
+
+type relationTestPost struct {
+	ID         int64                 `json:"id"`
+	ReviewerID *int64                `json:"reviewer_id"`
+	Reviewer   *relationTestCustomer `json:"reviewer" db:",belongs_to,fk=reviewer_id"`
+}
+
+func Test_SQLTool_PreloadPointerKey(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectPrepare("SELECT id, reviewer_id FROM post").
+		ExpectQuery().
+		WillReturnRows(sqlmock.NewRows([]string{"id", "reviewer_id"}).AddRow(1, 10).AddRow(2, nil).AddRow(3, 10))
+	// nil key is skipped, same key of different pointers is queried once
+	mock.ExpectPrepare("SELECT id, name FROM customer WHERE id IN (?)").
+		ExpectQuery().
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(10, "alice"))
+
+	// real code
+	sqlTool := sqltool.NewTool(context.Background(), db)
+	sqlTool.PrepareSelect(&relationTestPost{}, sqltool.Preload("Reviewer"))
+
+	var posts []relationTestPost
+	err = sqlTool.SelectAll(&posts, "SELECT id, reviewer_id FROM post")
+	if err != nil {
+		t.Fatalf("error when select with preload, details: %v", err)
+	}
+
+	if posts[0].Reviewer == nil || posts[0].Reviewer.Name != "alice" || posts[1].Reviewer != nil ||
+		posts[2].Reviewer == nil || posts[2].Reviewer.Name != "alice" {
+		t.Fatalf("unexpected preloaded reviewers %+v", posts)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unfulfilled expectations, details: %v", err)
+	}
+}
+
+type relationTestAuthor struct {
+	ID    int64
+	Name  string
+	Books []*relationTestBook `db:",has_many,fk=author_id,table=book"`
+}
+
+type relationTestBook struct {
+	ID       int64
+	AuthorID int64
+	Title    string `db:",encrypted"`
+}
+
+func Test_SQLTool_PreloadWithModelOptions(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	naming := sqltool.NamingStrategyOpt(sqltool.SnakeCaseNaming)
+	encryptor := sqltool.EncryptorOpt(sqltool.NewAESGCMEncryptor(sqltool.NewKeyring("v1", map[string][]byte{"v1": bytes.Repeat([]byte{1}, 32)})))
+
+	sqlTool := sqltool.NewTool(context.Background(), db)
+	err = sqlTool.PrepareInsertE(&relationTestBook{AuthorID: 1, Title: "secret"}, naming, encryptor)
+	if err != nil {
+		t.Fatalf("error when prepare insert encrypted book, details: %v", err)
+	}
+	title := sqlTool.GetInsertValues()[1]
+
+	mock.ExpectPrepare("SELECT id, name FROM author").
+		ExpectQuery().
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "alice"))
+	// related model is prepared with naming strategy and encryptor of tool
+	mock.ExpectPrepare("SELECT id, author_id, title FROM book WHERE author_id IN (?)").
+		ExpectQuery().
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "author_id", "title"}).AddRow(1, 1, title))
+
+	// real code
+	sqlTool = sqltool.NewTool(context.Background(), db)
+	sqlTool.PrepareSelect(&relationTestAuthor{}, naming, encryptor, sqltool.Preload("Books"))
+
+	var authors []relationTestAuthor
+	err = sqlTool.SelectAll(&authors, "SELECT id, name FROM author")
+	if err != nil {
+		t.Fatalf("error when select with preload, details: %v", err)
+	}
+
+	if len(authors[0].Books) != 1 || authors[0].Books[0].Title != "secret" {
+		t.Fatalf("unexpected preloaded books %+v", authors[0].Books)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unfulfilled expectations, details: %v", err)
+	}
+}
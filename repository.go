@@ -0,0 +1,239 @@
+package sqltool
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// Repository -- generic repository of model T stored in table, options are declared once at NewRepository and
+// model metadata is parsed once then reused by every call. Snapshots of DirtyTrackingOpt are shared by every call
+// until UntrackAll
+type Repository[T any] struct {
+	exec       Executor
+	table      string
+	prototypes map[actionType]SQLTool
+	snapshots  *snapshotStore
+}
+
+// NewRepository -- create repository of model T, exec is usually *sql.DB. Transaction attached to context of each call
+// by WithTx or SQLTool.Context is joined automatically
+func NewRepository[T any](exec Executor, table string, opts ...sqlToolOpt) *Repository[T] {
+	r := &Repository[T]{
+		exec:       exec,
+		table:      table,
+		prototypes: make(map[actionType]SQLTool),
+		snapshots:  newSnapshotStore(),
+	}
+
+	for _, action := range []actionType{insertAction, selectAction, updateAction, deleteAction} {
+		st := NewToolFromExecutor(context.Background(), exec)
+		st.snapshots = r.snapshots
+		st.prepare(action, new(T), opts)
+		r.prototypes[action] = st
+	}
+
+	return r
+}
+
+// Table -- name of table of repository
+func (r *Repository[T]) Table() string {
+	return r.table
+}
+
+// UntrackAll -- remove all snapshots of models tracked by DirtyTrackingOpt
+func (r *Repository[T]) UntrackAll() {
+	r.snapshots.clear()
+}
+
+// tool -- copy of prepared tool for action, run with ctx, snapshots are shared with other calls
+func (r *Repository[T]) tool(ctx context.Context, action actionType) *SQLTool {
+	st := r.prototypes[action]
+	st.ctx = ctx
+	st.txn, _ = ctx.Value(txContextKey{}).(*transaction)
+	return &st
+}
+
+// FindByPK -- find model by primary key values in order of primary key columns
+func (r *Repository[T]) FindByPK(ctx context.Context, keys ...interface{}) (*T, error) {
+	st := r.tool(ctx, selectAction)
+
+	m := new(T)
+	primaryKeys := st.primaryKeyColumns()
+	if len(keys) != len(primaryKeys) {
+		return nil, fmt.Errorf("expected %d primary key values but got %d", len(primaryKeys), len(keys))
+	}
+
+	ve := reflect.ValueOf(m).Elem()
+	for index, column := range primaryKeys {
+		field := ve.FieldByName(st.column2FieldName[column])
+		key := reflect.ValueOf(keys[index])
+		if !field.IsValid() || !key.IsValid() || !key.Type().ConvertibleTo(field.Type()) {
+			return nil, fmt.Errorf("invalid value %v of primary key column %s", keys[index], column)
+		}
+		field.Set(key.Convert(field.Type()))
+	}
+
+	err := st.FindByPK(r.table, m)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// FindWhere -- find models match condition, empty slice is returned when no row matched
+func (r *Repository[T]) FindWhere(ctx context.Context, cond squirrel.Sqlizer, opts ...sqlToolOpt) ([]T, error) {
+	st := r.tool(ctx, selectAction)
+
+	var res []T
+	err := st.FindWhere(r.table, &res, cond, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Insert -- insert model, serial field is set by last insert id when it has zero value
+func (r *Repository[T]) Insert(ctx context.Context, m *T) (sql.Result, error) {
+	st := r.tool(ctx, insertAction)
+	err := st.PrepareInsertE(m)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, err := squirrel.Insert(r.table).
+		Columns(st.GetColumns()...).
+		Values(st.GetInsertValues()...).
+		PlaceholderFormat(st.dialect.placeholder()).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := st.Exec(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	st.setSerialField(m, res)
+	return res, nil
+}
+
+// InsertBatch -- insert models by one INSERT command
+func (r *Repository[T]) InsertBatch(ctx context.Context, ms []*T) (sql.Result, error) {
+	if len(ms) == 0 {
+		return nil, errors.New("no model to insert")
+	}
+
+	st := r.tool(ctx, insertAction)
+	st.prepare(insertAction, ms[0], nil)
+
+	builder := squirrel.Insert(r.table).
+		Columns(st.GetColumns()...).
+		PlaceholderFormat(st.dialect.placeholder())
+	for _, m := range ms {
+		if st.validate {
+			err := st.validateModel(m)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		values, err := st.PrepareValuesE(m)
+		if err != nil {
+			return nil, err
+		}
+		builder = builder.Values(values...)
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	return st.Exec(query, args...)
+}
+
+// Update -- update model by primary key, version column is checked when model has one
+func (r *Repository[T]) Update(ctx context.Context, m *T) (sql.Result, error) {
+	return r.tool(ctx, updateAction).UpdateByPK(r.table, m)
+}
+
+// Delete -- delete model by primary key, model is soft deleted when it has soft delete column
+func (r *Repository[T]) Delete(ctx context.Context, m *T) (sql.Result, error) {
+	return r.tool(ctx, deleteAction).DeleteByPK(r.table, m)
+}
+
+// Count -- count rows match condition, soft deleted rows are excluded
+func (r *Repository[T]) Count(ctx context.Context, cond squirrel.Sqlizer) (int64, error) {
+	st := r.tool(ctx, selectAction)
+
+	query, args, err := r.selectBuilder(st, "COUNT(*)", cond).ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = st.SelectScalar(&count, query, args...)
+	return count, err
+}
+
+// Exists -- check if any row match condition, soft deleted rows are excluded
+func (r *Repository[T]) Exists(ctx context.Context, cond squirrel.Sqlizer) (bool, error) {
+	st := r.tool(ctx, selectAction)
+
+	query, args, err := r.selectBuilder(st, "1", cond).Limit(1).ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	var one int64
+	err = st.SelectScalar(&one, query, args...)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *Repository[T]) selectBuilder(st *SQLTool, column string, cond squirrel.Sqlizer) squirrel.SelectBuilder {
+	builder := squirrel.Select(column).
+		From(r.table).
+		Where(st.softDeleteWhere()).
+		PlaceholderFormat(st.dialect.placeholder())
+	if cond != nil {
+		builder = builder.Where(cond)
+	}
+
+	return builder
+}
+
+// setSerialField -- set last insert id to serial field of model when it has zero value
+func (st *SQLTool) setSerialField(i interface{}, res sql.Result) {
+	fieldName, ok := st.column2FieldName[st.serialColumn]
+	if !ok {
+		return
+	}
+
+	field := reflect.Indirect(reflect.ValueOf(i)).FieldByName(fieldName)
+	if !field.IsZero() {
+		return
+	}
+
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		id, err := res.LastInsertId()
+		if err == nil {
+			field.SetInt(id)
+		}
+	}
+}
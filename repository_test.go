@@ -0,0 +1,141 @@
+package sqltool_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Masterminds/squirrel"
+	"github.com/wizk3y/go-sqltool"
+)
+
+type repositoryTestUser struct {
+	ID        int64  `json:"id"`
+	Username  string `json:"username"`
+	Version   int64  `json:"version" db:",version"`
+	DeletedAt int64  `json:"deleted_at" db:",soft_delete"`
+}
+
+func Test_Repository(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	columns := []string{"id", "username", "version", "deleted_at"}
+	mock.ExpectPrepare("INSERT INTO user (username,version) VALUES ($1,$2)").
+		ExpectExec().
+		WithArgs("sample", 1).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+	mock.ExpectPrepare("SELECT id, username, version, deleted_at FROM user WHERE id = $1 AND deleted_at IS NULL").
+		ExpectQuery().
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(7, "sample", 1, nil))
+	mock.ExpectPrepare("UPDATE user SET username = $1, version = version + 1 WHERE id = $2 AND version = $3").
+		ExpectExec().
+		WithArgs("renamed", 7, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("SELECT COUNT(*) FROM user WHERE deleted_at IS NULL AND username LIKE $1").
+		ExpectQuery().
+		WithArgs("re%").
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(1))
+	mock.ExpectPrepare("SELECT 1 FROM user WHERE deleted_at IS NULL AND username = $1 LIMIT 1").
+		ExpectQuery().
+		WithArgs("sample").
+		WillReturnRows(sqlmock.NewRows([]string{"1"}))
+
+	// real code
+	repo := sqltool.NewRepository[repositoryTestUser](db, "user", sqltool.DialectOpt(sqltool.DialectPostgres))
+	ctx := context.Background()
+
+	user := repositoryTestUser{Username: "sample", Version: 1}
+	_, err = repo.Insert(ctx, &user)
+	if err != nil || user.ID != 7 {
+		t.Fatalf("error when insert, id: %d, details: %v", user.ID, err)
+	}
+
+	// inserted row is not soft deleted, it is found by primary key
+	found, err := repo.FindByPK(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("error when find inserted row by primary key, details: %v", err)
+	}
+	if found.ID != user.ID || found.Username != user.Username || found.Version != user.Version || found.DeletedAt != 0 {
+		t.Fatalf("expected inserted user %+v but got %+v", user, found)
+	}
+
+	found.Username = "renamed"
+	_, err = repo.Update(ctx, found)
+	if err != nil || found.Version != 2 {
+		t.Fatalf("error when update, version: %d, details: %v", found.Version, err)
+	}
+
+	count, err := repo.Count(ctx, squirrel.Like{"username": "re%"})
+	if err != nil || count != 1 {
+		t.Fatalf("error when count, got %d, details: %v", count, err)
+	}
+
+	exists, err := repo.Exists(ctx, squirrel.Eq{"username": "sample"})
+	if err != nil || exists {
+		t.Fatalf("error when check exists, got %v, details: %v", exists, err)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unfulfilled expectations, details: %v", err)
+	}
+}
+
+type repositoryTestProfile struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+func Test_Repository_DirtyTracking(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectPrepare("SELECT id, name, email FROM profile WHERE id = ?").
+		ExpectQuery().
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).AddRow(1, "alice", "a@b.c"))
+	// snapshot of FindByPK is kept for Update, only changed column is updated
+	mock.ExpectPrepare("UPDATE profile SET email = ? WHERE id = ?").
+		ExpectExec().
+		WithArgs("alice@b.c", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("UPDATE profile SET email = ?, name = ? WHERE id = ?").
+		ExpectExec().
+		WithArgs("alice@b.c", "bob", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// real code
+	repo := sqltool.NewRepository[repositoryTestProfile](db, "profile", sqltool.DirtyTrackingOpt(true))
+	ctx := context.Background()
+
+	found, err := repo.FindByPK(ctx, 1)
+	if err != nil {
+		t.Fatalf("error when find by primary key, details: %v", err)
+	}
+
+	found.Email = "alice@b.c"
+	_, err = repo.Update(ctx, found)
+	if err != nil {
+		t.Fatalf("error when update, details: %v", err)
+	}
+
+	// every column is updated after snapshots are removed
+	repo.UntrackAll()
+	found.Name = "bob"
+	_, err = repo.Update(ctx, found)
+	if err != nil {
+		t.Fatalf("error when update untracked model, details: %v", err)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unfulfilled expectations, details: %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+package sqltool
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wizk3y/go-sqltool/internal"
+)
+
+// nullTime -- same as sql.NullTime but also accept date/time text from driver which not parse time itself
+type nullTime struct {
+	Time  time.Time
+	Valid bool
+}
+
+func (nt *nullTime) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		nt.Time, nt.Valid = time.Time{}, false
+	case time.Time:
+		nt.Time, nt.Valid = v, true
+	case []byte:
+		nt.Time, nt.Valid = internal.ParseTime(string(v))
+	case string:
+		nt.Time, nt.Valid = internal.ParseTime(v)
+	default:
+		return fmt.Errorf("unsupported Scan, storing driver.Value type %T into type *time.Time", value)
+	}
+
+	return nil
+}
+
+type columnKind int
+
+const (
+	columnKindText columnKind = iota
+	columnKindInt
+	columnKindFloat
+	columnKindBool
+	columnKindDateTime
+	columnKindBinary
+	// columnKindTime -- time of day or duration, kept as text
+	columnKindTime
+	// columnKindArray -- postgres array, kept as text
+	columnKindArray
+)
+
+// databaseTypeKinds -- kind of database type name reported by mysql, postgres, sqlite and sqlserver drivers or
+// information_schema
+var databaseTypeKinds = map[string]columnKind{
+	"TINYINT": columnKindInt, "SMALLINT": columnKindInt, "MEDIUMINT": columnKindInt, "INT": columnKindInt,
+	"INTEGER": columnKindInt, "BIGINT": columnKindInt, "INT2": columnKindInt, "INT4": columnKindInt,
+	"INT8": columnKindInt, "SERIAL": columnKindInt, "BIGSERIAL": columnKindInt, "SMALLSERIAL": columnKindInt,
+	"YEAR": columnKindInt,
+
+	"DECIMAL": columnKindFloat, "NUMERIC": columnKindFloat, "FLOAT": columnKindFloat, "FLOAT4": columnKindFloat,
+	"FLOAT8": columnKindFloat, "DOUBLE": columnKindFloat, "DOUBLE PRECISION": columnKindFloat, "REAL": columnKindFloat,
+
+	"BOOL": columnKindBool, "BOOLEAN": columnKindBool,
+
+	"DATE": columnKindDateTime, "DATETIME": columnKindDateTime, "DATETIME2": columnKindDateTime,
+	"SMALLDATETIME": columnKindDateTime, "DATETIMEOFFSET": columnKindDateTime, "TIMESTAMP": columnKindDateTime,
+	"TIMESTAMPTZ": columnKindDateTime, "TIMESTAMP WITHOUT TIME ZONE": columnKindDateTime,
+	"TIMESTAMP WITH TIME ZONE": columnKindDateTime,
+
+	"TIME": columnKindTime, "TIMETZ": columnKindTime, "TIME WITHOUT TIME ZONE": columnKindTime,
+	"TIME WITH TIME ZONE": columnKindTime,
+
+	// data type of array column in information_schema of postgres
+	"ARRAY": columnKindArray,
+
+	"BLOB": columnKindBinary, "TINYBLOB": columnKindBinary, "MEDIUMBLOB": columnKindBinary,
+	"LONGBLOB": columnKindBinary, "BINARY": columnKindBinary, "VARBINARY": columnKindBinary, "BYTEA": columnKindBinary,
+}
+
+// columnKindOf -- classify database type name reported by driver, unknown type is kept as text
+func columnKindOf(databaseType string) columnKind {
+	t := strings.ToUpper(strings.TrimSpace(databaseType))
+
+	// postgres array type name is element type prefixed by underscore, e.g. _INT4
+	if strings.HasPrefix(t, "_") {
+		return columnKindArray
+	}
+
+	// declared type of sqlite may have size, e.g. VARCHAR(255)
+	if index := strings.Index(t, "("); index >= 0 {
+		t = strings.TrimSpace(t[:index])
+	}
+	t = strings.TrimPrefix(t, "UNSIGNED ")
+
+	if kind, ok := databaseTypeKinds[t]; ok {
+		return kind
+	}
+
+	return columnKindText
+}
+
+// scanMapRow -- scan current row to map column - value, value is converted by database type of column
+func (st *SQLTool) scanMapRow(rows *sql.Rows, columnTypes []*sql.ColumnType) (map[string]interface{}, error) {
+	raws := make([]interface{}, len(columnTypes))
+	dest := make([]interface{}, len(columnTypes))
+	for index := range raws {
+		dest[index] = &raws[index]
+	}
+
+	err := rows.Scan(dest...)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]interface{}, len(columnTypes))
+	for index, ct := range columnTypes {
+		kind := columnKindOf(ct.DatabaseTypeName())
+		if internal.IsStringSliceContains(st.dateTimeColumns, ct.Name()) {
+			kind = columnKindDateTime
+		}
+
+		m[ct.Name()], err = st.convertMapValue(raws[index], kind)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %v", ct.Name(), err)
+		}
+	}
+
+	return m, nil
+}
+
+func (st *SQLTool) convertMapValue(raw interface{}, kind columnKind) (interface{}, error) {
+	switch v := raw.(type) {
+	case nil:
+		return nil, nil
+	case time.Time:
+		return internal.TimestampByUnit(v, st.dateTimeUnit), nil
+	case []byte:
+		if kind == columnKindBinary {
+			var tmp = make([]byte, len(v))
+			copy(tmp, v)
+			return tmp, nil
+		}
+
+		return st.convertMapText(string(v), kind)
+	case string:
+		return st.convertMapText(v, kind)
+	}
+
+	return raw, nil
+}
+
+func (st *SQLTool) convertMapText(s string, kind columnKind) (interface{}, error) {
+	switch kind {
+	case columnKindInt:
+		return strconv.ParseInt(s, 10, 64)
+	case columnKindFloat:
+		return strconv.ParseFloat(s, 64)
+	case columnKindBool:
+		return strconv.ParseBool(s)
+	case columnKindDateTime:
+		t, ok := internal.ParseTime(s)
+		if !ok {
+			return nil, nil
+		}
+
+		return internal.TimestampByUnit(t, st.dateTimeUnit), nil
+	}
+
+	return s, nil
+}
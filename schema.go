@@ -0,0 +1,247 @@
+package sqltool
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/wizk3y/go-sqltool/internal"
+)
+
+// ErrSchemaDrift -- model does not match table, errors.Is(err, ErrSchemaDrift) is true for *SchemaError
+var ErrSchemaDrift = errors.New("schema drift")
+
+// SchemaIssueKind -- kind of mismatch between model and table
+type SchemaIssueKind string
+
+const (
+	// SchemaMissingColumn -- column of model does not exist in table
+	SchemaMissingColumn SchemaIssueKind = "missing_column"
+	// SchemaRequiredColumn -- NOT NULL column without default is not in model, INSERT by model fails
+	SchemaRequiredColumn SchemaIssueKind = "required_column"
+	// SchemaNullability -- NULL of column is not handled same way by model and table
+	SchemaNullability SchemaIssueKind = "nullability"
+	// SchemaTypeMismatch -- type of column can not be scanned to or written from field
+	SchemaTypeMismatch SchemaIssueKind = "type_mismatch"
+)
+
+// SchemaIssue -- one mismatch between model and table
+type SchemaIssue struct {
+	Kind    SchemaIssueKind
+	Column  string
+	Message string
+}
+
+// SchemaError -- all mismatches between model and table, returned by CheckSchema
+type SchemaError struct {
+	Table  string
+	Issues []SchemaIssue
+}
+
+func (e *SchemaError) Error() string {
+	msgs := make([]string, 0, len(e.Issues))
+	for _, issue := range e.Issues {
+		msgs = append(msgs, fmt.Sprintf("%s %s: %s", issue.Kind, issue.Column, issue.Message))
+	}
+
+	return fmt.Sprintf("schema drift of table %s: %s", e.Table, strings.Join(msgs, "; "))
+}
+
+func (e *SchemaError) Is(target error) bool {
+	return target == ErrSchemaDrift
+}
+
+// tableColumn -- column of table read from information_schema or PRAGMA table_info
+type tableColumn struct {
+	name          string
+	dataType      string
+	nullable      bool
+	hasDefault    bool
+	autoIncrement bool
+}
+
+// CheckSchema -- compare model prepared with opts to table of live database, *SchemaError is returned when they do not
+// match. Dialect is set by DialectOpt, columns are read from information_schema or PRAGMA table_info with DialectSQLite
+func CheckSchema(ctx context.Context, exec Executor, table string, i interface{}, opts ...sqlToolOpt) error {
+	st := NewToolFromExecutor(ctx, exec)
+	st.prepare(selectAction, i, opts)
+
+	tableColumns, err := st.tableColumns(ctx, table)
+	if err != nil {
+		return err
+	}
+	if len(tableColumns) == 0 {
+		return fmt.Errorf("table %s is not found", table)
+	}
+
+	columns := make(map[string]tableColumn, len(tableColumns))
+	for _, tc := range tableColumns {
+		columns[tc.name] = tc
+	}
+
+	serr := &SchemaError{Table: table}
+	addIssue := func(kind SchemaIssueKind, column string, format string, args ...interface{}) {
+		serr.Issues = append(serr.Issues, SchemaIssue{Kind: kind, Column: column, Message: fmt.Sprintf(format, args...)})
+	}
+
+	modelColumns := st.schemaColumns()
+	for _, column := range modelColumns {
+		tc, ok := columns[strings.ToLower(column)]
+		if !ok {
+			addIssue(SchemaMissingColumn, column, "column of field %s does not exist", st.column2FieldName[column])
+			continue
+		}
+
+		vType := st.column2Type[column]
+		writeNull := vType.Kind() == reflect.Ptr || internal.IsStringSliceContains(st.nullableColumns, column)
+		if writeNull && !tc.nullable && !tc.autoIncrement {
+			addIssue(SchemaNullability, column, "NULL may be written to NOT NULL column")
+		}
+		if !writeNull && tc.nullable && !st.isDateTimeColumn(column) && !internal.IsStringSliceContains(st.primaryKeyColumns(), column) {
+			addIssue(SchemaNullability, column, "NULL is read as zero value, use pointer field or NullableColumnsOpt")
+		}
+
+		if !st.isCompatibleType(column, columnKindOf(tc.dataType)) {
+			addIssue(SchemaTypeMismatch, column, "%s column is not compatible with field of type %s", tc.dataType, vType)
+		}
+	}
+
+	inModel := make(map[string]bool, len(modelColumns))
+	for _, column := range modelColumns {
+		inModel[strings.ToLower(column)] = true
+	}
+	for _, tc := range tableColumns {
+		if inModel[tc.name] {
+			continue
+		}
+
+		if !tc.nullable && !tc.hasDefault && !tc.autoIncrement {
+			addIssue(SchemaRequiredColumn, tc.name, "NOT NULL column without default is not in model")
+		}
+	}
+
+	if len(serr.Issues) > 0 {
+		return serr
+	}
+
+	return nil
+}
+
+// schemaColumns -- columns of model read or written by tool, columns excluded by AllowColumnsOpt/IgnoreColumnsOpt are skipped
+func (st *SQLTool) schemaColumns() []string {
+	columns := make([]string, 0, len(st.allColumns))
+	for _, column := range st.allColumns {
+		if len(st.allowColumns) > 0 {
+			if _, ok := st.allowColumns[column]; !ok {
+				continue
+			}
+		} else if _, ok := st.ignoreColumns[column]; ok {
+			continue
+		}
+
+		columns = append(columns, column)
+	}
+
+	return columns
+}
+
+// isCompatibleType -- field of column can be scanned from and written to database type of kind
+func (st *SQLTool) isCompatibleType(column string, kind columnKind) bool {
+	vType := st.column2Type[column]
+
+	if st.isDateTimeColumn(column) {
+		return kind == columnKindDateTime
+	}
+	if encrypted, _ := st.encryptionMode(column); encrypted {
+		return kind == columnKindText || kind == columnKindBinary
+	}
+	if _, ok := lookupEnum(vType); ok {
+		return kind == columnKindText || kind == columnKindInt
+	}
+
+	return isCompatibleKind(vType, kind)
+}
+
+func isCompatibleKind(vType reflect.Type, kind columnKind) bool {
+	switch vType.Kind() {
+	case reflect.String:
+		return true
+	case reflect.Bool:
+		return kind == columnKindBool || kind == columnKindInt
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return kind == columnKindInt
+	case reflect.Float32, reflect.Float64:
+		return kind == columnKindFloat || kind == columnKindInt
+	case reflect.Ptr:
+		if elem := vType.Elem().Kind(); elem != reflect.Struct && elem != reflect.Slice && elem != reflect.Map {
+			return isCompatibleKind(vType.Elem(), kind)
+		}
+	}
+
+	// bytes and values serialized by codec
+	return kind == columnKindText || kind == columnKindBinary || kind == columnKindArray
+}
+
+// tableColumns -- columns of table in order, name is lower case
+func (st *SQLTool) tableColumns(ctx context.Context, table string) ([]tableColumn, error) {
+	var (
+		query string
+		args  []interface{}
+	)
+	switch st.dialect {
+	case DialectSQLite:
+		query = `PRAGMA table_info("` + strings.ReplaceAll(table, `"`, `""`) + `")`
+	case DialectPostgres:
+		query = "SELECT column_name, data_type, is_nullable, column_default, " +
+			"CASE WHEN is_identity = 'YES' THEN 'auto_increment' ELSE '' END " +
+			"FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = $1"
+		args = []interface{}{table}
+	default:
+		query = "SELECT column_name, data_type, is_nullable, column_default, extra " +
+			"FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ?"
+		args = []interface{}{table}
+	}
+
+	rows, err := st.queryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make([]tableColumn, 0)
+	for rows.Next() {
+		var tc tableColumn
+		if st.dialect == DialectSQLite {
+			var (
+				cid, notNull, pk int64
+				dflt             sql.NullString
+			)
+			err = rows.Scan(&cid, &tc.name, &tc.dataType, &notNull, &dflt, &pk)
+			tc.nullable = notNull == 0 && pk == 0
+			tc.hasDefault = dflt.Valid
+			// INTEGER PRIMARY KEY is alias of rowid
+			tc.autoIncrement = pk > 0 && strings.EqualFold(tc.dataType, "INTEGER")
+		} else {
+			var (
+				isNullable  string
+				dflt, extra sql.NullString
+			)
+			err = rows.Scan(&tc.name, &tc.dataType, &isNullable, &dflt, &extra)
+			tc.nullable = strings.EqualFold(isNullable, "YES")
+			tc.hasDefault = dflt.Valid || strings.Contains(strings.ToUpper(extra.String), "GENERATED")
+			tc.autoIncrement = strings.Contains(strings.ToLower(extra.String), "auto_increment")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		tc.name = strings.ToLower(tc.name)
+		columns = append(columns, tc)
+	}
+
+	return columns, rows.Err()
+}
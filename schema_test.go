@@ -0,0 +1,112 @@
+package sqltool_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/wizk3y/go-sqltool"
+)
+
+type schemaTestProduct struct {
+	ID        int64   `json:"id"`
+	Name      string  `json:"name"`
+	Price     int64   `json:"price"`
+	Note      *string `json:"note"`
+	Stock     int64   `json:"stock"`
+	Color     string  `json:"color"`
+	CreatedAt int64   `json:"created_at"`
+	Discount  *int64  `json:"discount"`
+}
+
+type schemaTestTagged struct {
+	ID        int64    `json:"id"`
+	Tags      []string `json:"tags"`
+	CreatedAt int64    `json:"created_at"`
+}
+
+func Test_CheckSchema(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	mysqlQuery := "SELECT column_name, data_type, is_nullable, column_default, extra FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ?"
+	mock.ExpectPrepare(mysqlQuery).
+		ExpectQuery().
+		WithArgs("product").
+		WillReturnRows(sqlmock.NewRows([]string{"column_name", "data_type", "is_nullable", "column_default", "extra"}).
+			AddRow("id", "bigint", "NO", nil, "auto_increment").
+			AddRow("name", "varchar", "NO", nil, "").
+			AddRow("price", "decimal", "NO", "0", "").
+			AddRow("note", "text", "NO", nil, "").
+			AddRow("stock", "int", "YES", nil, "").
+			AddRow("created_at", "datetime", "YES", nil, "DEFAULT_GENERATED").
+			AddRow("sku", "varchar", "NO", nil, "").
+			AddRow("discount", "int", "NO", "0", "").
+			AddRow("updated_at", "timestamp", "NO", "CURRENT_TIMESTAMP", "DEFAULT_GENERATED on update CURRENT_TIMESTAMP"))
+
+	err = sqltool.CheckSchema(context.Background(), db, "product", &schemaTestProduct{},
+		sqltool.DateTimeColumnsOpt([]string{"created_at"}))
+	if !errors.Is(err, sqltool.ErrSchemaDrift) {
+		t.Fatalf("expected ErrSchemaDrift but got %v", err)
+	}
+
+	var serr *sqltool.SchemaError
+	if !errors.As(err, &serr) {
+		t.Fatalf("expected *SchemaError but got %T", err)
+	}
+	expected := []sqltool.SchemaIssue{
+		{Kind: sqltool.SchemaTypeMismatch, Column: "price"},
+		{Kind: sqltool.SchemaNullability, Column: "note"},
+		{Kind: sqltool.SchemaNullability, Column: "stock"},
+		{Kind: sqltool.SchemaMissingColumn, Column: "color"},
+		// default is not used when NULL is written explicitly
+		{Kind: sqltool.SchemaNullability, Column: "discount"},
+		{Kind: sqltool.SchemaRequiredColumn, Column: "sku"},
+	}
+	if len(serr.Issues) != len(expected) {
+		t.Fatalf("expected %d issues but got %v", len(expected), serr.Issues)
+	}
+	for index, issue := range expected {
+		if serr.Issues[index].Kind != issue.Kind || serr.Issues[index].Column != issue.Column {
+			t.Fatalf("expected issue %+v but got %+v", issue, serr.Issues[index])
+		}
+	}
+
+	// sqlite
+	mock.ExpectPrepare(`PRAGMA table_info("product")`).
+		ExpectQuery().
+		WillReturnRows(sqlmock.NewRows([]string{"cid", "name", "type", "notnull", "dflt_value", "pk"}).
+			AddRow(0, "id", "INTEGER", 0, nil, 1).
+			AddRow(1, "name", "TEXT", 1, nil, 0).
+			AddRow(2, "price", "INTEGER", 1, "0", 0))
+
+	err = sqltool.CheckSchema(context.Background(), db, "product", &schemaTestProduct{},
+		sqltool.DialectOpt(sqltool.DialectSQLite), sqltool.AllowColumnsOpt([]string{"id", "name", "price"}))
+	if err != nil {
+		t.Fatalf("expected model match sqlite table but got %v", err)
+	}
+
+	// postgres
+	mock.ExpectPrepare("SELECT column_name, data_type, is_nullable, column_default, CASE WHEN is_identity = 'YES' THEN 'auto_increment' ELSE '' END FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = $1").
+		ExpectQuery().
+		WithArgs("tagged").
+		WillReturnRows(sqlmock.NewRows([]string{"column_name", "data_type", "is_nullable", "column_default", "case"}).
+			AddRow("id", "bigint", "NO", nil, "auto_increment").
+			AddRow("tags", "ARRAY", "YES", nil, "").
+			AddRow("created_at", "timestamp with time zone", "YES", nil, ""))
+
+	err = sqltool.CheckSchema(context.Background(), db, "tagged", &schemaTestTagged{},
+		sqltool.DialectOpt(sqltool.DialectPostgres), sqltool.DateTimeColumnsOpt([]string{"created_at"}),
+		sqltool.NullableColumnsOpt([]string{"tags"}))
+	if err != nil {
+		t.Fatalf("expected model match postgres table but got %v", err)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unfulfilled expectations, details: %v", err)
+	}
+}
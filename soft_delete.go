@@ -0,0 +1,80 @@
+package sqltool
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/wizk3y/go-sqltool/internal"
+)
+
+type deletedScope int
+
+const (
+	deletedScopeDefault deletedScope = iota
+	deletedScopeWith
+	deletedScopeOnly
+)
+
+// getSoftDeleteColumn -- soft delete column set by SoftDeleteColumnOpt or `db:",soft_delete"` tag
+func (st *SQLTool) getSoftDeleteColumn() string {
+	if len(st.softDeleteColumn) > 0 {
+		return st.softDeleteColumn
+	}
+
+	return st.taggedColumn("soft_delete")
+}
+
+// softDeleteWhere -- condition of soft delete column by deleted scope, nil when there is no condition
+func (st *SQLTool) softDeleteWhere() squirrel.Sqlizer {
+	softDeleteColumn := st.getSoftDeleteColumn()
+	if len(softDeleteColumn) == 0 {
+		return nil
+	}
+
+	switch st.deletedScope {
+	case deletedScopeWith:
+		return nil
+	case deletedScopeOnly:
+		return squirrel.NotEq{softDeleteColumn: nil}
+	}
+
+	return squirrel.Eq{softDeleteColumn: nil}
+}
+
+// softDeleteValue -- value of soft delete column, timestamp by DateTimeUnitOpt when column is not date/time column
+func (st *SQLTool) softDeleteValue(now time.Time) interface{} {
+	softDeleteColumn := st.getSoftDeleteColumn()
+	if st.isDateTimeColumn(softDeleteColumn) {
+		return now
+	}
+
+	vType, ok := st.column2Type[softDeleteColumn]
+	if ok {
+		switch internal.Deref(vType).Kind() {
+		case reflect.Int, reflect.Int32, reflect.Int64:
+			return internal.TimestampByUnit(now, st.dateTimeUnit)
+		}
+	}
+
+	return now
+}
+
+// setSoftDeleteField -- set deleted time to soft delete field of model
+func (st *SQLTool) setSoftDeleteField(i interface{}, now time.Time) {
+	fieldName, ok := st.column2FieldName[st.getSoftDeleteColumn()]
+	if !ok {
+		return
+	}
+
+	field := reflect.Indirect(reflect.ValueOf(i)).FieldByName(fieldName)
+	ts := internal.TimestampByUnit(now, st.dateTimeUnit)
+	switch field.Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		field.SetInt(ts)
+	case reflect.Ptr:
+		if field.Type().Elem().Kind() == reflect.Int64 {
+			field.Set(reflect.ValueOf(&ts))
+		}
+	}
+}
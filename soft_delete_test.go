@@ -0,0 +1,149 @@
+package sqltool_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Masterminds/squirrel"
+	"github.com/wizk3y/go-sqltool"
+)
+
+type softDeleteTestPost struct {
+	ID        int64  `json:"id"`
+	Title     string `json:"title"`
+	DeletedAt *int64 `json:"deleted_at" db:",soft_delete"`
+}
+
+func Test_SQLTool_SoftDelete(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	columns := []string{"id", "title", "deleted_at"}
+	mock.ExpectPrepare("SELECT id, title, deleted_at FROM post WHERE id = ? AND deleted_at IS NULL").
+		ExpectQuery().
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "hello", nil))
+	mock.ExpectPrepare("UPDATE post SET deleted_at = ? WHERE deleted_at IS NULL AND id = ?").
+		ExpectExec().
+		WithArgs(sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("SELECT id, title, deleted_at FROM post WHERE deleted_at IS NOT NULL AND title = ?").
+		ExpectQuery().
+		WithArgs("hello").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "hello", "1680220668"))
+	mock.ExpectPrepare("DELETE FROM post WHERE id = ?").
+		ExpectExec().
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// real code
+	sqlTool := sqltool.NewTool(context.Background(), db)
+
+	post := softDeleteTestPost{ID: 1}
+	err = sqlTool.FindByPK("post", &post)
+	if err != nil {
+		t.Fatalf("error when find by primary key, details: %v", err)
+	}
+
+	_, err = sqlTool.DeleteByPK("post", &post, sqltool.DateTimeUnitOpt("s"))
+	if err != nil {
+		t.Fatalf("error when soft delete, details: %v", err)
+	}
+	if post.DeletedAt == nil {
+		t.Fatalf("expected deleted time is set to model")
+	}
+
+	var deleted []softDeleteTestPost
+	err = sqlTool.FindWhere("post", &deleted, squirrel.Eq{"title": "hello"}, sqltool.OnlyDeleted())
+	if err != nil || len(deleted) != 1 {
+		t.Fatalf("error when find deleted posts, details: %v", err)
+	}
+
+	_, err = sqlTool.HardDelete("post", &post)
+	if err != nil {
+		t.Fatalf("error when hard delete, details: %v", err)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unfulfilled expectations, details: %v", err)
+	}
+}
+
+type softDeleteTestComment struct {
+	ID        int64  `json:"id"`
+	Body      string `json:"body"`
+	Version   int64  `json:"version" db:",version"`
+	DeletedAt int64  `json:"deleted_at"`
+}
+
+func Test_SQLTool_PrepareDelete(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectPrepare("DELETE FROM comment WHERE id = ? AND version = ?").
+		ExpectExec().
+		WithArgs(1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("UPDATE comment SET deleted_at = ?, version = version + 1 WHERE deleted_at IS NULL AND id = ? AND version = ?").
+		ExpectExec().
+		WithArgs(sqlmock.AnyArg(), 2, 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// real code
+	sqlTool := sqltool.NewTool(context.Background(), db)
+
+	comment := softDeleteTestComment{ID: 1, Version: 2}
+	err = sqlTool.PrepareDelete(&comment)
+	if err != nil {
+		t.Fatalf("error when prepare delete, details: %v", err)
+	}
+
+	query, args, err := squirrel.Delete("comment").
+		Where(sqlTool.GetDeleteWhere()).
+		ToSql()
+	if err != nil {
+		t.Fatalf("error when build query")
+	}
+
+	_, err = sqlTool.Exec(query, args...)
+	if err != nil {
+		t.Fatalf("error when execute delete query, details: %v", err)
+	}
+
+	// soft delete by model-driven delete
+	comment = softDeleteTestComment{ID: 2, Version: 5}
+	_, err = sqlTool.DeleteByPK("comment", &comment, sqltool.SoftDeleteColumnOpt("deleted_at"))
+	if err != nil {
+		t.Fatalf("error when soft delete, details: %v", err)
+	}
+	if comment.Version != 6 || comment.DeletedAt == 0 {
+		t.Fatalf("expected version and deleted time are updated, got %+v", comment)
+	}
+
+	// condition match no row when primary key is not prepared
+	err = sqlTool.PrepareDelete(&softDeleteTestComment{})
+	if err == nil {
+		t.Fatalf("expected error when prepare delete without primary key")
+	}
+
+	query, _, err = squirrel.Delete("comment").
+		Where(sqlTool.GetDeleteWhere()).
+		ToSql()
+	if err != nil {
+		t.Fatalf("error when build query")
+	}
+	if query != "DELETE FROM comment WHERE (1=0)" {
+		t.Fatalf("expected condition match no row, got %s", query)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unfulfilled expectations, details: %v", err)
+	}
+}
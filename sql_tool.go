@@ -4,6 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"reflect"
+	"time"
+
+	"github.com/Masterminds/squirrel"
 )
 
 type actionType string
@@ -17,21 +20,28 @@ const (
 
 // SQLTool --
 type SQLTool struct {
-	ctx           context.Context
-	db            *sql.DB
-	isTransaction bool
-	tx            *sql.Tx
+	ctx    context.Context
+	exec   Executor
+	txn    *transaction
+	scopes []txScope
 
 	actionType actionType
 	// related to struct
 	modelPkgPath     string
 	modelName        string
 	columns          []string
+	allColumns       []string
 	column2FieldName map[string]string
 	column2Type      map[string]reflect.Type
-	values           []interface{}
+	column2Tag       map[string]map[string]string
+	// related to nested struct
+	column2FieldIndex   map[string][]int
+	column2NestedGroups map[string][]string
+	relations           map[string]relation
+	values              []interface{}
 	// related to opt
 	serialColumn              string
+	primaryKeys               []string
 	nullableColumns           []string
 	dateTimeColumns           []string
 	dateTimeUnit              string
@@ -39,14 +49,57 @@ type SQLTool struct {
 	autoUpdateDateTimeColumns map[string]bool
 	allowColumns              map[string]bool
 	ignoreColumns             map[string]bool
+	scanMode                  ScanMode
+	allowEmptyResult          bool
+	strictOneRow              bool
+	dialect                   Dialect
+	dirtyTracking             bool
+	versionColumn             string
+	softDeleteColumn          string
+	deletedScope              deletedScope
+	preloads                  []string
+	namingStrategy            NamingStrategy
+	columnCodecs              map[string]Codec
+	enumPolicy                EnumPolicy
+	encryptor                 Encryptor
+	encryptedColumns          map[string]bool
+	validate                  bool
+	// related to dirty tracking
+	snapshots    *snapshotStore
+	dirtyColumns map[string]bool
+	// related to optimistic locking
+	versionValue interface{}
+	// related to delete
+	deleteWhere squirrel.Eq
+	deleteTime  time.Time
+	// error of PrepareInsert/PrepareUpdate/PrepareValues, returned by next Exec
+	prepareErr error
+}
+
+// NewTool -- generic sql tool, transaction attached to ctx by WithTx is joined automatically
+func NewTool(ctx context.Context, db *sql.DB) SQLTool {
+	return NewToolFromExecutor(ctx, db)
+}
+
+// NewToolFromTx -- sql tool run inside transaction started elsewhere, the transaction is not commited by tool
+func NewToolFromTx(ctx context.Context, tx *sql.Tx) SQLTool {
+	return NewToolFromExecutor(WithTx(ctx, tx), tx)
+}
+
+// NewToolFromConn -- sql tool run on single connection, useful when depend on session variables
+func NewToolFromConn(ctx context.Context, conn *sql.Conn) SQLTool {
+	return NewToolFromExecutor(ctx, conn)
 }
 
-// NewTool -- generic sql tool
-func NewTool(ctx context.Context, db *sql.DB) (st SQLTool) {
+// NewToolFromExecutor -- sql tool run on any executor, executor must implement BeginTx to start transaction and
+// TxWrapper to apply inside transaction
+func NewToolFromExecutor(ctx context.Context, exec Executor) (st SQLTool) {
 	st.ctx = ctx
-	st.db = db
+	st.exec = exec
+	st.txn, _ = ctx.Value(txContextKey{}).(*transaction)
 	// opt default
 	st.serialColumn = "id"
 	st.dateTimeUnit = "ns"
+	st.dialect = DialectMySQL
 	return
 }
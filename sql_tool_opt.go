@@ -19,6 +19,23 @@ func (o serialColumnOpt) Apply(st *SQLTool) bool {
 	return false
 }
 
+type primaryKeyOpt []string
+
+// PrimaryKeyOpt -- set columns identify a row, same as `db:",pk"` tag. Non-serial key columns are kept in INSERT and
+// excluded from UPDATE SET list, default is serial column
+func PrimaryKeyOpt(columns []string) sqlToolOpt {
+	return primaryKeyOpt(columns)
+}
+
+func (o primaryKeyOpt) Apply(st *SQLTool) bool {
+	if reflect.DeepEqual([]string(o), st.primaryKeys) {
+		return false
+	}
+
+	st.primaryKeys = o
+	return true
+}
+
 type nullableColumnsOpt []string
 
 // NullableColumnsOpt -- column not set value to nil instead of zero value of type
@@ -145,3 +162,269 @@ func (o ignoreColumnsOpt) Apply(st *SQLTool) bool {
 	st.ignoreColumns = mapColumns
 	return true
 }
+
+// ScanMode -- define how list select handle row failed to scan
+type ScanMode int
+
+const (
+	// ScanModeSkip -- log and skip failed row, default of Select
+	ScanModeSkip ScanMode = iota + 1
+	// ScanModeAbort -- stop at first failed row and return its *RowError, default of SelectAll
+	ScanModeAbort
+	// ScanModeCollect -- skip failed rows and return RowErrors listing all of them
+	ScanModeCollect
+)
+
+type scanModeOpt ScanMode
+
+// ScanModeOpt -- set how Select/SelectAll handle row failed to scan
+func ScanModeOpt(mode ScanMode) sqlToolOpt {
+	return scanModeOpt(mode)
+}
+
+func (o scanModeOpt) Apply(st *SQLTool) bool {
+	st.scanMode = ScanMode(o)
+
+	return false
+}
+
+type allowEmptyResultOpt bool
+
+// AllowEmptyResultOpt -- Select/SelectAll return empty slice and nil error instead of sql.ErrNoRows when no row matched
+func AllowEmptyResultOpt(allow bool) sqlToolOpt {
+	return allowEmptyResultOpt(allow)
+}
+
+func (o allowEmptyResultOpt) Apply(st *SQLTool) bool {
+	st.allowEmptyResult = bool(o)
+
+	return false
+}
+
+type strictOneRowOpt bool
+
+// StrictOneRowOpt -- SelectOne return ErrTooManyRows when query return more than one row
+func StrictOneRowOpt(strict bool) sqlToolOpt {
+	return strictOneRowOpt(strict)
+}
+
+func (o strictOneRowOpt) Apply(st *SQLTool) bool {
+	st.strictOneRow = bool(o)
+
+	return false
+}
+
+type dialectOpt Dialect
+
+// DialectOpt -- set SQL dialect used to build query inside tool, default is DialectMySQL
+func DialectOpt(dialect Dialect) sqlToolOpt {
+	return dialectOpt(dialect)
+}
+
+func (o dialectOpt) Apply(st *SQLTool) bool {
+	st.dialect = Dialect(o)
+
+	return false
+}
+
+type dirtyTrackingOpt bool
+
+// DirtyTrackingOpt -- snapshot models loaded by Select/SelectOne, so PrepareUpdate only keep changed columns.
+// Snapshots are kept by tool until Untrack/UntrackAll
+func DirtyTrackingOpt(enable bool) sqlToolOpt {
+	return dirtyTrackingOpt(enable)
+}
+
+func (o dirtyTrackingOpt) Apply(st *SQLTool) bool {
+	st.dirtyTracking = bool(o)
+
+	return false
+}
+
+type versionColumnOpt string
+
+// VersionColumnOpt -- set version column for optimistic locking, same as `db:",version"` tag
+func VersionColumnOpt(column string) sqlToolOpt {
+	return versionColumnOpt(column)
+}
+
+func (o versionColumnOpt) Apply(st *SQLTool) bool {
+	st.versionColumn = string(o)
+
+	return false
+}
+
+type softDeleteColumnOpt string
+
+// SoftDeleteColumnOpt -- set soft delete column, same as `db:",soft_delete"` tag. DeleteByPK set deleted time instead of
+// delete row, FindByPK/FindWhere exclude deleted rows. Column is excluded from INSERT and UPDATE, it is only written by
+// GetSoftDeleteMap
+func SoftDeleteColumnOpt(column string) sqlToolOpt {
+	return softDeleteColumnOpt(column)
+}
+
+func (o softDeleteColumnOpt) Apply(st *SQLTool) bool {
+	if st.softDeleteColumn == string(o) {
+		return false
+	}
+
+	// column is excluded from INSERT and UPDATE, parse again
+	st.softDeleteColumn = string(o)
+	return true
+}
+
+type deletedScopeOpt deletedScope
+
+// WithDeleted -- include soft deleted rows in FindByPK/FindWhere
+func WithDeleted() sqlToolOpt {
+	return deletedScopeOpt(deletedScopeWith)
+}
+
+// OnlyDeleted -- only soft deleted rows in FindByPK/FindWhere
+func OnlyDeleted() sqlToolOpt {
+	return deletedScopeOpt(deletedScopeOnly)
+}
+
+func (o deletedScopeOpt) Apply(st *SQLTool) bool {
+	st.deletedScope = deletedScope(o)
+
+	return false
+}
+
+type preloadOpt []string
+
+// Preload -- load related rows declared by relation tag for next Select/SelectAll/SelectOne, one extra query is issued
+// per relation
+func Preload(relations ...string) sqlToolOpt {
+	return preloadOpt(relations)
+}
+
+func (o preloadOpt) Apply(st *SQLTool) bool {
+	st.preloads = o
+
+	return false
+}
+
+type namingStrategyOpt struct {
+	strategy NamingStrategy
+}
+
+// NamingStrategyOpt -- name column of exported field without json tag by strategy, e.g. SnakeCaseNaming. Field without
+// json tag is skipped by default
+func NamingStrategyOpt(strategy NamingStrategy) sqlToolOpt {
+	return namingStrategyOpt{strategy: strategy}
+}
+
+func (o namingStrategyOpt) Apply(st *SQLTool) bool {
+	// funcs are not comparable, columns are always parsed again
+	st.namingStrategy = o.strategy
+	return true
+}
+
+type codecOpt struct {
+	column string
+	codec  Codec
+}
+
+// CodecOpt -- serialize slice/struct/map/pointer column by codec, e.g. GobCodec. Same as `db:",codec=name"` tag with
+// codec registered by RegisterCodec, default is encoding/json
+func CodecOpt(column string, codec Codec) sqlToolOpt {
+	return codecOpt{column: column, codec: codec}
+}
+
+func (o codecOpt) Apply(st *SQLTool) bool {
+	// copy on write, map may be shared by copies of tool
+	codecs := make(map[string]Codec, len(st.columnCodecs)+1)
+	for column, codec := range st.columnCodecs {
+		codecs[column] = codec
+	}
+	codecs[o.column] = o.codec
+	st.columnCodecs = codecs
+
+	return false
+}
+
+// EnumPolicy -- define how scan handle database value not registered in enum
+type EnumPolicy int
+
+const (
+	// EnumPolicyError -- fail row with error wrapping ErrInvalidEnum, default policy
+	EnumPolicyError EnumPolicy = iota
+	// EnumPolicyZero -- leave field with zero value
+	EnumPolicyZero
+	// EnumPolicyKeep -- convert database value to type of field as is
+	EnumPolicyKeep
+)
+
+type enumPolicyOpt EnumPolicy
+
+// EnumPolicyOpt -- set how scan handle database value not registered by RegisterEnum/RegisterEnumMapping
+func EnumPolicyOpt(policy EnumPolicy) sqlToolOpt {
+	return enumPolicyOpt(policy)
+}
+
+func (o enumPolicyOpt) Apply(st *SQLTool) bool {
+	st.enumPolicy = EnumPolicy(o)
+
+	return false
+}
+
+type encryptorOpt struct {
+	encryptor Encryptor
+}
+
+// EncryptorOpt -- set encryptor of encrypted columns, e.g. NewAESGCMEncryptor(NewKeyring("v1", keys))
+func EncryptorOpt(encryptor Encryptor) sqlToolOpt {
+	return encryptorOpt{encryptor: encryptor}
+}
+
+func (o encryptorOpt) Apply(st *SQLTool) bool {
+	st.encryptor = o.encryptor
+
+	return false
+}
+
+type encryptedColumnsOpt struct {
+	columns       []string
+	deterministic bool
+}
+
+// EncryptedColumnsOpt -- encrypt columns with random nonce, same as `db:",encrypted"` tag
+func EncryptedColumnsOpt(columns []string) sqlToolOpt {
+	return encryptedColumnsOpt{columns: columns}
+}
+
+// DeterministicEncryptedColumnsOpt -- encrypt columns so same value has same ciphertext and can be queried by equality
+// with SQLTool.EncryptValue, same as `db:",encrypted=deterministic"` tag. Rows must be encrypted again after key rotation
+// to be matched by equality
+func DeterministicEncryptedColumnsOpt(columns []string) sqlToolOpt {
+	return encryptedColumnsOpt{columns: columns, deterministic: true}
+}
+
+func (o encryptedColumnsOpt) Apply(st *SQLTool) bool {
+	// copy on write, map may be shared by copies of tool
+	columns := make(map[string]bool, len(st.encryptedColumns)+len(o.columns))
+	for column, deterministic := range st.encryptedColumns {
+		columns[column] = deterministic
+	}
+	for _, column := range o.columns {
+		columns[column] = o.deterministic
+	}
+	st.encryptedColumns = columns
+
+	return false
+}
+
+type validateOpt bool
+
+// ValidateOpt -- validate model by `validate` tags and Validate method before PrepareInsert/PrepareUpdate.
+// *ValidationError is returned by PrepareInsertE/PrepareUpdateE, or by next Exec after PrepareInsert/PrepareUpdate
+func ValidateOpt(enable bool) sqlToolOpt {
+	return validateOpt(enable)
+}
+
+func (o validateOpt) Apply(st *SQLTool) bool {
+	st.validate = bool(o)
+
+	return false
+}
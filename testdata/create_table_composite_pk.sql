@@ -0,0 +1,6 @@
+CREATE TABLE "membership" (
+  "group_id" INTEGER NOT NULL,
+  "user_id" INTEGER NOT NULL,
+  "role" TEXT NOT NULL,
+  PRIMARY KEY ("group_id", "user_id")
+);
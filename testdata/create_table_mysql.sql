@@ -0,0 +1,19 @@
+CREATE TABLE `article` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT,
+  `slug` VARCHAR(120) NOT NULL,
+  `title` VARCHAR(255) NOT NULL,
+  `author_id` INT NOT NULL,
+  `published` TINYINT(1) NOT NULL,
+  `score` DOUBLE NOT NULL,
+  `views` BIGINT UNSIGNED NOT NULL,
+  `summary` VARCHAR(255),
+  `tags` JSON,
+  `meta` JSON,
+  `cover` BLOB,
+  `status` SMALLINT NOT NULL,
+  `created_at` DATETIME(3) NOT NULL,
+  `deleted_at` DATETIME(3),
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_article_slug` (`slug`),
+  KEY `idx_article_author_time` (`author_id`, `created_at`)
+);
@@ -0,0 +1,19 @@
+CREATE TABLE "article" (
+  "id" BIGSERIAL,
+  "slug" VARCHAR(120) NOT NULL,
+  "title" TEXT NOT NULL,
+  "author_id" INT NOT NULL,
+  "published" BOOLEAN NOT NULL,
+  "score" DOUBLE PRECISION NOT NULL,
+  "views" NUMERIC(20) NOT NULL,
+  "summary" TEXT,
+  "tags" TEXT[],
+  "meta" JSONB,
+  "cover" BYTEA,
+  "status" SMALLINT NOT NULL,
+  "created_at" TIMESTAMP NOT NULL,
+  "deleted_at" TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX "uk_article_slug" ON "article" ("slug");
+CREATE INDEX "idx_article_author_time" ON "article" ("author_id", "created_at");
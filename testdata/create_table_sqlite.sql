@@ -0,0 +1,18 @@
+CREATE TABLE "article" (
+  "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+  "slug" VARCHAR(120) NOT NULL,
+  "title" TEXT NOT NULL,
+  "author_id" INTEGER NOT NULL,
+  "published" INTEGER NOT NULL,
+  "score" REAL NOT NULL,
+  "views" INTEGER NOT NULL,
+  "summary" TEXT,
+  "tags" TEXT,
+  "meta" TEXT,
+  "cover" BLOB,
+  "status" INTEGER NOT NULL,
+  "created_at" DATETIME NOT NULL,
+  "deleted_at" DATETIME
+);
+CREATE UNIQUE INDEX "uk_article_slug" ON "article" ("slug");
+CREATE INDEX "idx_article_author_time" ON "article" ("author_id", "created_at");
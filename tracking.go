@@ -0,0 +1,156 @@
+package sqltool
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// snapshotStore -- snapshots of tracked models by snapshot key, shared by copies of tool, e.g. calls of Repository
+type snapshotStore struct {
+	mu        sync.RWMutex
+	snapshots map[string]map[string]interface{}
+}
+
+func newSnapshotStore() *snapshotStore {
+	return &snapshotStore{snapshots: make(map[string]map[string]interface{})}
+}
+
+func (s *snapshotStore) get(key string) (map[string]interface{}, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot, ok := s.snapshots[key]
+	return snapshot, ok
+}
+
+func (s *snapshotStore) set(key string, snapshot map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.snapshots[key] = snapshot
+}
+
+func (s *snapshotStore) delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.snapshots, key)
+}
+
+func (s *snapshotStore) clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.snapshots = make(map[string]map[string]interface{})
+}
+
+// Track -- snapshot current values of model, later PrepareUpdate of the model only keep changed columns.
+// Model must have value of primary key
+func (st *SQLTool) Track(i interface{}) {
+	if reflect.TypeOf(i).String() != st.modelName {
+		st.PrepareSelect(i)
+	}
+
+	st.track(reflect.ValueOf(i))
+}
+
+// Untrack -- remove snapshot of model, later PrepareUpdate of the model keep all columns
+func (st *SQLTool) Untrack(i interface{}) {
+	key, ok := st.snapshotKey(reflect.ValueOf(i))
+	if !ok || st.snapshots == nil {
+		return
+	}
+
+	st.snapshots.delete(key)
+}
+
+// UntrackAll -- remove all snapshots kept by tool, call when tracked models are no longer updated by long-lived tool
+func (st *SQLTool) UntrackAll() {
+	if st.snapshots != nil {
+		st.snapshots.clear()
+	}
+}
+
+func (st *SQLTool) track(v reflect.Value) {
+	key, ok := st.snapshotKey(v)
+	if !ok {
+		return
+	}
+
+	if st.snapshots == nil {
+		st.snapshots = newSnapshotStore()
+	}
+
+	ve := reflect.Indirect(v)
+	snapshot := make(map[string]interface{}, len(st.column2FieldName))
+	for column, fieldName := range st.column2FieldName {
+		snapshot[column] = snapshotValue(ve.FieldByName(fieldName))
+	}
+
+	st.snapshots.set(key, snapshot)
+}
+
+// changedColumns -- columns of model changed since snapshot, nil when model is not tracked
+func (st *SQLTool) changedColumns(i interface{}) map[string]bool {
+	v := reflect.ValueOf(i)
+	key, ok := st.snapshotKey(v)
+	if !ok || st.snapshots == nil {
+		return nil
+	}
+
+	snapshot, ok := st.snapshots.get(key)
+	if !ok {
+		return nil
+	}
+
+	ve := reflect.Indirect(v)
+	changed := make(map[string]bool)
+	for _, column := range st.columns {
+		old, ok := snapshot[column]
+		if !ok || !reflect.DeepEqual(old, snapshotValue(ve.FieldByName(st.column2FieldName[column]))) {
+			changed[column] = true
+		}
+	}
+
+	return changed
+}
+
+// snapshotKey -- identify model by its type and quoted primary key values, false when primary key is missing or serial
+// column is zero
+func (st *SQLTool) snapshotKey(v reflect.Value) (string, bool) {
+	ve := reflect.Indirect(v)
+	if ve.Kind() != reflect.Struct {
+		return "", false
+	}
+
+	values := []string{strconv.Quote(st.modelName)}
+	for _, column := range st.primaryKeyColumns() {
+		fieldName, ok := st.column2FieldName[column]
+		if !ok {
+			return "", false
+		}
+
+		field := ve.FieldByName(fieldName)
+		if column == st.serialColumn && field.IsZero() {
+			return "", false
+		}
+		values = append(values, strconv.Quote(fmt.Sprint(field.Interface())))
+	}
+
+	return strings.Join(values, ","), true
+}
+
+// snapshotValue -- copy of field value, complex value is encoded so later in-place change is detected
+func snapshotValue(field reflect.Value) interface{} {
+	switch field.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Struct, reflect.Ptr:
+		data, _ := json.Marshal(field.Interface())
+		return string(data)
+	}
+
+	return field.Interface()
+}
@@ -1,45 +1,349 @@
 package sqltool
 
-import "fmt"
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+)
 
-// Begin -- start transaction
+// ErrTxNotSupported -- returned by Begin when executor of tool can not start transaction
+var ErrTxNotSupported = errors.New("executor does not support starting transaction")
+
+// ErrRollbackOnly -- returned by Commit when a joined scope has rolled back the transaction
+var ErrRollbackOnly = errors.New("transaction has been marked as rollback-only")
+
+// Propagation -- define how Begin behave when tool already run inside a transaction
+type Propagation int
+
+const (
+	// PropagationRequired -- join current transaction, begin new one if there is none
+	PropagationRequired Propagation = iota
+	// PropagationRequiresNew -- always begin new transaction, current transaction is suspended until new one finished
+	PropagationRequiresNew
+	// PropagationSupports -- join current transaction, run without transaction if there is none
+	PropagationSupports
+	// PropagationNested -- create savepoint inside current transaction, begin new one if there is none
+	PropagationNested
+)
+
+type txContextKey struct{}
+
+// activeTransactions -- transactions begun by tools, so WithTx share state with the tool owned the transaction.
+// Transaction is removed when it is committed or rolled back, or when context of its tool is done, same as *sql.Tx
+// holding its connection, transaction abandoned with context never done is kept
+var activeTransactions sync.Map
+
+// transaction -- physical transaction, shared between tools joined it
+type transaction struct {
+	tx           *sql.Tx
+	savepoints   int
+	rollbackOnly bool
+	// callbacks of root scope and each opening savepoint
+	callbacks []*txCallbacks
+	// closed when transaction begun by tool is committed or rolled back
+	done chan struct{}
+}
+
+type txCallbacks struct {
+	onCommit   []func()
+	onRollback []func()
+}
+
+func newTransaction(tx *sql.Tx) *transaction {
+	return &transaction{
+		tx:        tx,
+		callbacks: []*txCallbacks{{}},
+		done:      make(chan struct{}),
+	}
+}
+
+// activate -- register transaction begun by tool until it is finished or ctx is done
+func (txn *transaction) activate(ctx context.Context) {
+	activeTransactions.Store(txn.tx, txn)
+
+	ctxDone := ctx.Done()
+	if ctxDone == nil {
+		return
+	}
+
+	go func() {
+		select {
+		case <-ctxDone:
+			activeTransactions.Delete(txn.tx)
+		case <-txn.done:
+		}
+	}()
+}
+
+// finish -- unregister transaction committed or rolled back by its owner
+func (txn *transaction) finish() {
+	activeTransactions.Delete(txn.tx)
+	close(txn.done)
+}
+
+func (txn *transaction) currentCallbacks() *txCallbacks {
+	return txn.callbacks[len(txn.callbacks)-1]
+}
+
+func (txn *transaction) pushCallbacks() {
+	txn.callbacks = append(txn.callbacks, &txCallbacks{})
+}
+
+// releaseCallbacks -- move callbacks of released savepoint to its parent
+func (txn *transaction) releaseCallbacks() {
+	if len(txn.callbacks) < 2 {
+		return
+	}
+
+	cb := txn.currentCallbacks()
+	txn.callbacks = txn.callbacks[:len(txn.callbacks)-1]
+
+	parent := txn.currentCallbacks()
+	parent.onCommit = append(parent.onCommit, cb.onCommit...)
+	parent.onRollback = append(parent.onRollback, cb.onRollback...)
+}
+
+// discardCallbacks -- drop callbacks of rolled back savepoint, its rollback callbacks are run immediately
+func (txn *transaction) discardCallbacks() {
+	if len(txn.callbacks) < 2 {
+		return
+	}
+
+	cb := txn.currentCallbacks()
+	txn.callbacks = txn.callbacks[:len(txn.callbacks)-1]
+
+	runCallbacks(cb.onRollback)
+}
+
+func (txn *transaction) runCommitCallbacks() {
+	for _, cb := range txn.callbacks {
+		runCallbacks(cb.onCommit)
+	}
+}
+
+func (txn *transaction) runRollbackCallbacks() {
+	for _, cb := range txn.callbacks {
+		runCallbacks(cb.onRollback)
+	}
+}
+
+func runCallbacks(fns []func()) {
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+type txScopeKind int
+
+const (
+	txScopeOwner txScopeKind = iota
+	txScopeJoined
+	txScopeSavepoint
+	txScopeNone
+)
+
+// txScope -- a Begin call, closed by Commit or Rollback
+type txScope struct {
+	kind      txScopeKind
+	savepoint string
+	suspended *transaction
+}
+
+// WithTx -- attach transaction to context, tool created with this context will run inside the transaction
+func WithTx(ctx context.Context, tx *sql.Tx) context.Context {
+	if txn, ok := ctx.Value(txContextKey{}).(*transaction); ok && txn.tx == tx {
+		return ctx
+	}
+
+	if txn, ok := activeTransactions.Load(tx); ok {
+		return context.WithValue(ctx, txContextKey{}, txn)
+	}
+
+	return context.WithValue(ctx, txContextKey{}, newTransaction(tx))
+}
+
+// TxFromContext -- get transaction attached to context by WithTx or SQLTool.Context
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	txn, ok := ctx.Value(txContextKey{}).(*transaction)
+	if !ok {
+		return nil, false
+	}
+
+	return txn.tx, true
+}
+
+// Context -- get context of tool, current transaction is attached so tools created from it will join the transaction
+func (st *SQLTool) Context() context.Context {
+	if st.txn == nil {
+		return st.ctx
+	}
+
+	return context.WithValue(st.ctx, txContextKey{}, st.txn)
+}
+
+// Begin -- start transaction, join current transaction if exists
 func (st *SQLTool) Begin() error {
-	tx, err := st.db.Begin()
+	return st.BeginWithPropagation(PropagationRequired)
+}
+
+// BeginWithPropagation -- start transaction scope with specific propagation
+func (st *SQLTool) BeginWithPropagation(p Propagation) error {
+	switch p {
+	case PropagationRequired:
+		if st.txn == nil {
+			return st.beginNew()
+		}
+
+		st.scopes = append(st.scopes, txScope{kind: txScopeJoined})
+	case PropagationRequiresNew:
+		return st.beginNew()
+	case PropagationSupports:
+		if st.txn == nil {
+			st.scopes = append(st.scopes, txScope{kind: txScopeNone})
+			return nil
+		}
+
+		st.scopes = append(st.scopes, txScope{kind: txScopeJoined})
+	case PropagationNested:
+		if st.txn == nil {
+			return st.beginNew()
+		}
+
+		st.txn.savepoints++
+		savepoint := fmt.Sprintf("sqltool_sp_%d", st.txn.savepoints)
+		_, err := st.executor().ExecContext(st.ctx, "SAVEPOINT "+savepoint)
+		if err != nil {
+			return err
+		}
+
+		st.txn.pushCallbacks()
+		st.scopes = append(st.scopes, txScope{kind: txScopeSavepoint, savepoint: savepoint})
+	default:
+		return fmt.Errorf("unknown transaction propagation %d", p)
+	}
+
+	return nil
+}
+
+func (st *SQLTool) beginNew() error {
+	beginner, ok := st.exec.(txBeginner)
+	if !ok {
+		return ErrTxNotSupported
+	}
+
+	tx, err := beginner.BeginTx(st.ctx, nil)
 	if err != nil {
 		return err
 	}
 
-	st.tx = tx
-	st.isTransaction = true
+	st.scopes = append(st.scopes, txScope{kind: txScopeOwner, suspended: st.txn})
+	st.txn = newTransaction(tx)
+	st.txn.activate(st.ctx)
 	return nil
 }
 
-// Commit -- commit transaction
+func (st *SQLTool) popScope() (txScope, bool) {
+	if len(st.scopes) == 0 {
+		return txScope{}, false
+	}
+
+	scope := st.scopes[len(st.scopes)-1]
+	st.scopes = st.scopes[:len(st.scopes)-1]
+	return scope, true
+}
+
+// Commit -- commit transaction then run OnCommit callbacks, transaction joined from context or outer scope is committed by its owner
 func (st *SQLTool) Commit() error {
-	if !st.isTransaction {
+	scope, ok := st.popScope()
+	if !ok {
 		fmt.Printf("[sqltool] tx not found")
 		return nil
 	}
 
-	err := st.tx.Commit()
-	if err != nil {
-		return err
+	switch scope.kind {
+	case txScopeOwner:
+		txn := st.txn
+		st.txn = scope.suspended
+		txn.finish()
+
+		if txn.rollbackOnly {
+			err := txn.tx.Rollback()
+			txn.runRollbackCallbacks()
+			if err != nil {
+				return err
+			}
+
+			return ErrRollbackOnly
+		}
+
+		err := txn.tx.Commit()
+		if err != nil {
+			txn.runRollbackCallbacks()
+			return err
+		}
+
+		txn.runCommitCallbacks()
+	case txScopeSavepoint:
+		_, err := st.executor().ExecContext(st.ctx, "RELEASE SAVEPOINT "+scope.savepoint)
+		if err != nil {
+			return err
+		}
+
+		st.txn.releaseCallbacks()
 	}
 
-	st.isTransaction = false
-	st.tx = nil
 	return nil
 }
 
-// Rollback -- rollback transaction if transaction not commited
+// Rollback -- rollback transaction if transaction not commited then run OnRollback callbacks, joined transaction is marked as rollback-only
 func (st *SQLTool) Rollback() error {
-	if !st.isTransaction {
+	scope, ok := st.popScope()
+	if !ok {
 		return nil
 	}
 
-	err := st.tx.Rollback()
+	switch scope.kind {
+	case txScopeOwner:
+		txn := st.txn
+		st.txn = scope.suspended
+		txn.finish()
+
+		err := txn.tx.Rollback()
+		txn.runRollbackCallbacks()
+		return err
+	case txScopeJoined:
+		st.txn.rollbackOnly = true
+	case txScopeSavepoint:
+		_, err := st.executor().ExecContext(st.ctx, "ROLLBACK TO SAVEPOINT "+scope.savepoint)
+		if err != nil {
+			return err
+		}
+
+		st.txn.discardCallbacks()
+	}
+
+	return nil
+}
+
+// OnCommit -- register callback run in order after transaction commited, run immediately when tool is not in transaction
+func (st *SQLTool) OnCommit(fn func()) {
+	if st.txn == nil {
+		fn()
+		return
+	}
+
+	cb := st.txn.currentCallbacks()
+	cb.onCommit = append(cb.onCommit, fn)
+}
+
+// OnRollback -- register callback run in order after transaction rolled back, ignored when tool is not in transaction
+func (st *SQLTool) OnRollback(fn func()) {
+	if st.txn == nil {
+		return
+	}
 
-	st.isTransaction = false
-	st.tx = nil
-	return err
+	cb := st.txn.currentCallbacks()
+	cb.onRollback = append(cb.onRollback, fn)
 }
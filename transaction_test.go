@@ -0,0 +1,162 @@
+package sqltool_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/wizk3y/go-sqltool"
+)
+
+func Test_SQLTool_TxPropagation(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("DELETE FROM user WHERE id = ?").
+		ExpectExec().
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO audit (action) VALUES (?)").
+		ExpectExec().
+		WithArgs("delete").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	mock.ExpectCommit()
+
+	// real code
+	outer := sqltool.NewTool(context.Background(), db)
+	err = outer.Begin()
+	if err != nil {
+		t.Fatalf("error when begin transaction, details: %v", err)
+	}
+	defer outer.Rollback()
+
+	// repository function join outer transaction through context
+	inner := sqltool.NewTool(outer.Context(), db)
+	err = inner.Begin()
+	if err != nil {
+		t.Fatalf("error when join transaction, details: %v", err)
+	}
+	_, err = inner.Exec("DELETE FROM user WHERE id = ?", 1)
+	if err != nil {
+		t.Fatalf("error when execute delete query, details: %v", err)
+	}
+	err = inner.Commit()
+	if err != nil {
+		t.Fatalf("error when commit joined transaction, details: %v", err)
+	}
+
+	// audit log is written in its own transaction
+	audit := sqltool.NewTool(outer.Context(), db)
+	err = audit.BeginWithPropagation(sqltool.PropagationRequiresNew)
+	if err != nil {
+		t.Fatalf("error when begin new transaction, details: %v", err)
+	}
+	_, err = audit.Exec("INSERT INTO audit (action) VALUES (?)", "delete")
+	if err != nil {
+		t.Fatalf("error when execute insert query, details: %v", err)
+	}
+	err = audit.Commit()
+	if err != nil {
+		t.Fatalf("error when commit new transaction, details: %v", err)
+	}
+
+	err = outer.Commit()
+	if err != nil {
+		t.Fatalf("error when commit transaction, details: %v", err)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unfulfilled expectations, details: %v", err)
+	}
+}
+
+func Test_SQLTool_TxRollbackOnly(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	// real code
+	outer := sqltool.NewTool(context.Background(), db)
+	err = outer.Begin()
+	if err != nil {
+		t.Fatalf("error when begin transaction, details: %v", err)
+	}
+
+	tx, ok := sqltool.TxFromContext(outer.Context())
+	if !ok || tx == nil {
+		t.Fatalf("expected transaction attached to context")
+	}
+
+	inner := sqltool.NewTool(sqltool.WithTx(context.Background(), tx), db)
+	_ = inner.Begin()
+	_ = inner.Rollback()
+
+	err = outer.Commit()
+	if err != sqltool.ErrRollbackOnly {
+		t.Fatalf("expected ErrRollbackOnly, details: %v", err)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unfulfilled expectations, details: %v", err)
+	}
+}
+
+func Test_SQLTool_TxCallbacks(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT sqltool_sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT sqltool_sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	// real code
+	var events []string
+	st := sqltool.NewTool(context.Background(), db)
+	err = st.Begin()
+	if err != nil {
+		t.Fatalf("error when begin transaction, details: %v", err)
+	}
+	st.OnCommit(func() { events = append(events, "user created") })
+
+	err = st.BeginWithPropagation(sqltool.PropagationNested)
+	if err != nil {
+		t.Fatalf("error when create savepoint, details: %v", err)
+	}
+	st.OnCommit(func() { events = append(events, "coupon applied") })
+	st.OnRollback(func() { events = append(events, "coupon released") })
+	err = st.Rollback()
+	if err != nil {
+		t.Fatalf("error when rollback to savepoint, details: %v", err)
+	}
+
+	st.OnCommit(func() { events = append(events, "mail sent") })
+	err = st.Commit()
+	if err != nil {
+		t.Fatalf("error when commit transaction, details: %v", err)
+	}
+
+	expected := []string{"coupon released", "user created", "mail sent"}
+	if !reflect.DeepEqual(events, expected) {
+		t.Fatalf("expected callbacks %v, got %v", expected, events)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unfulfilled expectations, details: %v", err)
+	}
+}
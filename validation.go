@@ -0,0 +1,195 @@
+package sqltool
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/wizk3y/go-sqltool/internal"
+)
+
+// ErrValidation -- model is invalid, errors.Is(err, ErrValidation) is true for *ValidationError
+var ErrValidation = errors.New("validation failed")
+
+// Validator -- model validate itself before write when ValidateOpt is enabled
+type Validator interface {
+	Validate() error
+}
+
+// FieldError -- one failed rule of field, Field and Column are empty for error returned by Validator
+type FieldError struct {
+	Field   string
+	Column  string
+	Rule    string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	if len(e.Field) == 0 {
+		return e.Message
+	}
+
+	return fmt.Sprintf("%s (column %s) %s", e.Field, e.Column, e.Message)
+}
+
+// ValidationError -- all failed rules of model, returned by PrepareInsertE/PrepareUpdateE or next Exec after
+// PrepareInsert/PrepareUpdate when ValidateOpt is enabled
+type ValidationError struct {
+	Model  string
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fe.Error())
+	}
+
+	return fmt.Sprintf("validation failed: %s: %s", e.Model, strings.Join(msgs, "; "))
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+var regexps sync.Map
+
+// validateModel -- check `validate:"required,min=1,max=50,regex=^[a-z]+$,oneof=a b c"` tags of column fields then
+// Validate method of model. min/max is length of string/slice/map and value of number, regex must not contain comma
+func (st *SQLTool) validateModel(i interface{}) error {
+	verr := &ValidationError{Model: st.modelName}
+
+	v := reflect.Indirect(reflect.ValueOf(i))
+	for _, column := range st.allColumns {
+		f, ok := v.Type().FieldByName(st.column2FieldName[column])
+		if !ok {
+			continue
+		}
+
+		tag := f.Tag.Get("validate")
+		if len(tag) == 0 {
+			continue
+		}
+
+		for _, rule := range internal.TrimedSpaceStringSlice(tag, ",") {
+			name, param := rule, ""
+			if i := strings.Index(rule, "="); i >= 0 {
+				name, param = rule[:i], rule[i+1:]
+			}
+
+			msg, err := validateRule(v.FieldByIndex(f.Index), name, param)
+			if err != nil {
+				return fmt.Errorf("invalid rule %s of field %s: %v", rule, f.Name, err)
+			}
+			if len(msg) > 0 {
+				verr.Errors = append(verr.Errors, FieldError{Field: f.Name, Column: column, Rule: name, Message: msg})
+			}
+		}
+	}
+
+	if validator, ok := i.(Validator); ok {
+		err := validator.Validate()
+		var modelErr *ValidationError
+		if errors.As(err, &modelErr) {
+			verr.Errors = append(verr.Errors, modelErr.Errors...)
+		} else if err != nil {
+			verr.Errors = append(verr.Errors, FieldError{Rule: "validate", Message: err.Error()})
+		}
+	}
+
+	if len(verr.Errors) > 0 {
+		return verr
+	}
+
+	return nil
+}
+
+// validateRule -- message of failed rule, empty when value pass. Rule except required is skipped for nil pointer
+func validateRule(field reflect.Value, name string, param string) (string, error) {
+	if name == "required" {
+		if field.IsZero() {
+			return "is required", nil
+		}
+		return "", nil
+	}
+
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return "", nil
+		}
+		field = field.Elem()
+	}
+
+	switch name {
+	case "min", "max":
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return "", err
+		}
+
+		var (
+			size float64
+			unit string
+		)
+		switch field.Kind() {
+		case reflect.String:
+			size, unit = float64(len([]rune(field.String()))), " characters"
+		case reflect.Slice, reflect.Map, reflect.Array:
+			size, unit = float64(field.Len()), " items"
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			size = float64(field.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			size = float64(field.Uint())
+		case reflect.Float32, reflect.Float64:
+			size = field.Float()
+		default:
+			return "", fmt.Errorf("%s is not supported", field.Kind())
+		}
+
+		if name == "min" && size < limit {
+			return fmt.Sprintf("must be at least %s%s", param, unit), nil
+		}
+		if name == "max" && size > limit {
+			return fmt.Sprintf("must be at most %s%s", param, unit), nil
+		}
+	case "regex":
+		if field.Kind() != reflect.String {
+			return "", fmt.Errorf("%s is not supported", field.Kind())
+		}
+
+		re, err := compileRegexp(param)
+		if err != nil {
+			return "", err
+		}
+		if !re.MatchString(field.String()) {
+			return fmt.Sprintf("must match %s", param), nil
+		}
+	case "oneof":
+		value := fmt.Sprint(field.Interface())
+		if !internal.IsStringSliceContains(strings.Fields(param), value) {
+			return fmt.Sprintf("must be one of %s", param), nil
+		}
+	default:
+		return "", errors.New("unknown rule")
+	}
+
+	return "", nil
+}
+
+func compileRegexp(expr string) (*regexp.Regexp, error) {
+	if re, ok := regexps.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	regexps.Store(expr, re)
+	return re, nil
+}
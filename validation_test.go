@@ -0,0 +1,93 @@
+package sqltool_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/wizk3y/go-sqltool"
+)
+
+type validationTestAccount struct {
+	ID       int64   `json:"id"`
+	Username string  `json:"username" validate:"required,min=3,max=8,regex=^[a-z]+$"`
+	Age      int64   `json:"age" validate:"min=18,max=120"`
+	Role     string  `json:"role" validate:"oneof=admin member"`
+	Nickname *string `json:"nickname" validate:"max=4"`
+	Password string  `json:"password"`
+	Confirm  string  `json:"-"`
+}
+
+func (a validationTestAccount) Validate() error {
+	if a.Password != a.Confirm {
+		return errors.New("password confirmation does not match")
+	}
+
+	return nil
+}
+
+func Test_SQLTool_Validate(t *testing.T) {
+	db, _, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("error when open mock database connection, details: %v", err)
+	}
+	defer db.Close()
+
+	sqlTool := sqltool.NewTool(context.Background(), db)
+
+	nickname := "longname"
+	account := validationTestAccount{Username: "Bob", Age: 12, Role: "guest", Nickname: &nickname, Password: "a", Confirm: "b"}
+
+	// validation is disabled by default
+	err = sqlTool.PrepareInsertE(&account)
+	if err != nil {
+		t.Fatalf("expected no validation by default but got %v", err)
+	}
+
+	err = sqlTool.PrepareInsertE(&account, sqltool.ValidateOpt(true))
+	if !errors.Is(err, sqltool.ErrValidation) {
+		t.Fatalf("expected ErrValidation but got %v", err)
+	}
+
+	var verr *sqltool.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *ValidationError but got %T", err)
+	}
+	expected := []sqltool.FieldError{
+		{Field: "Username", Column: "username", Rule: "regex"},
+		{Field: "Age", Column: "age", Rule: "min"},
+		{Field: "Role", Column: "role", Rule: "oneof"},
+		{Field: "Nickname", Column: "nickname", Rule: "max"},
+		{Rule: "validate"},
+	}
+	if len(verr.Errors) != len(expected) {
+		t.Fatalf("expected %d field errors but got %v", len(expected), verr.Errors)
+	}
+	for index, fe := range expected {
+		got := verr.Errors[index]
+		if got.Field != fe.Field || got.Column != fe.Column || got.Rule != fe.Rule {
+			t.Fatalf("expected field error %+v but got %+v", fe, got)
+		}
+	}
+
+	// valid model, nil pointer skip rules except required
+	account = validationTestAccount{Username: "bob", Age: 20, Role: "admin"}
+	err = sqlTool.PrepareUpdateE(&account)
+	if err != nil {
+		t.Fatalf("error when prepare update valid model, details: %v", err)
+	}
+
+	account.Username = ""
+	err = sqlTool.PrepareUpdateE(&account)
+	if !errors.As(err, &verr) || len(verr.Errors) != 3 || verr.Errors[0].Rule != "required" {
+		t.Fatalf("expected required, min and regex errors of username but got %v", err)
+	}
+
+	// invalid model does not panic, *ValidationError is returned by next Exec without executing query
+	sqlTool.PrepareUpdate(&account)
+	_, err = sqlTool.Exec("UPDATE account SET username = ? WHERE id = ?", "", 1)
+	if !errors.As(err, &verr) || verr.Errors[0].Rule != "required" {
+		t.Fatalf("expected *ValidationError when execute after prepare invalid model but got %v", err)
+	}
+}